@@ -0,0 +1,121 @@
+package uu
+
+import (
+	"bytes"
+)
+
+// tableMarker is the line that precedes the alphabet of an entry using a translation table, as written by early
+// encoders meant to pass through EBCDIC gateways
+const tableMarker = "table"
+
+// alphabetLength is the number of characters in an alphabet, one for each six-bit value
+const alphabetLength = 64
+
+// alphabet maps the characters of a custom alphabet to and from the standard one
+type alphabet struct {
+	chars      string
+	toStandard [256]byte
+}
+
+var errInvalidAlphabet = newError("Invalid alphabet")
+
+func newAlphabet(chars string) (*alphabet, error) {
+	if len(chars) != alphabetLength {
+		return nil, errInvalidAlphabet
+	}
+	a := &alphabet{chars: chars}
+	for v := 0; v < alphabetLength; v++ {
+		c := chars[v]
+		if a.toStandard[c] != 0 || c == '\r' || c == '\n' {
+			return nil, errInvalidAlphabet
+		}
+		a.toStandard[c] = toEncoded(uint32(v))
+	}
+	return a, nil
+}
+
+// alphabet returns the custom alphabet of a classic or XX entry, or nil if it uses the standard one
+func (fi *FileInfo) alphabet() (*alphabet, error) {
+	chars := fi.Alphabet
+	if fi.Encoding == XXEncoding {
+		chars = XXAlphabet
+	} else if chars == "" || fi.Encoding != UUEncoding {
+		return nil, nil
+	}
+	if fi.table == nil || fi.table.chars != chars {
+		table, err := newAlphabet(chars)
+		if err != nil {
+			return nil, err
+		}
+		fi.table = table
+	}
+	return fi.table, nil
+}
+
+// standardLine translates a payload line of an entry with a custom alphabet to the standard alphabet, reusing the
+// buffer pointed to by buf if it is not nil. Lines of entries using the standard alphabet are returned as is.
+func standardLine(fileInfo *FileInfo, in []byte, buf *[]byte) ([]byte, error) {
+	a, err := fileInfo.alphabet()
+	if a == nil || err != nil {
+		return in, err
+	}
+
+	var out []byte
+	if buf != nil {
+		out = (*buf)[:0]
+		defer func() { *buf = out }()
+	}
+	for i, c := range in {
+		s := a.toStandard[c]
+		if s == 0 {
+			if c == '\r' && i == len(in)-1 {
+				out = append(out, c)
+				break
+			}
+			return nil, newError("Character not in alphabet")
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// fromStandard translates encoded characters in the standard alphabet to the alphabet of the entry, in place
+func fromStandard(fileInfo *FileInfo, encoded []byte) {
+	a, _ := fileInfo.alphabet()
+	if a == nil {
+		return
+	}
+	for i, c := range encoded {
+		encoded[i] = a.chars[fromEncoded(c)]
+	}
+}
+
+// parseTable reads the lines of the alphabet following a table line
+func parseTable(reader func() ([]byte, error)) (string, error) {
+	var chars []byte
+	for len(chars) < alphabetLength {
+		line, err := reader()
+		if err != nil {
+			return "", err
+		}
+		chars = append(chars, bytes.TrimSuffix(line, []byte{'\r'})...)
+	}
+	if _, err := newAlphabet(string(chars)); err != nil {
+		return "", err
+	}
+	return string(chars), nil
+}
+
+func isTableLine(in []byte) bool {
+	return bytes.Equal(bytes.TrimRight(in, " \t\r"), []byte(tableMarker))
+}
+
+// formatTable appends the table line and the alphabet, split over two lines, to out
+func formatTable(fileInfo *FileInfo, out []byte) []byte {
+	out = append(out, tableMarker...)
+	out = append(out, '\n')
+	out = append(out, fileInfo.Alphabet[:alphabetLength/2]...)
+	out = append(out, '\n')
+	out = append(out, fileInfo.Alphabet[alphabetLength/2:]...)
+	return append(out, '\n')
+}
@@ -0,0 +1,155 @@
+package uu
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"testing"
+)
+
+const testAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+-"
+
+const tableInput = "begin 644 cat.txt\n" +
+	"table\n" +
+	"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdef\n" +
+	"ghijklmnopqrstuvwxyz0123456789+-\n" +
+	"DQ2F0\n" +
+	"A\n" +
+	"end\n"
+
+func TestNewAlphabet(t *testing.T) {
+	_, err := newAlphabet(testAlphabet)
+	assert.Nil(t, err)
+
+	_, err = newAlphabet(testAlphabet[1:])
+	assert.Equal(t, errInvalidAlphabet, err)
+	_, err = newAlphabet("A" + testAlphabet[1:63] + "A")
+	assert.Equal(t, errInvalidAlphabet, err)
+}
+
+func TestStandardLine(t *testing.T) {
+	info := &FileInfo{Encoding: UUEncoding, Alphabet: testAlphabet}
+
+	line, err := standardLine(info, []byte("DQ2F0\r"), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "#0V%T\r", string(line))
+
+	_, err = standardLine(info, []byte("DQ2F!"), nil)
+	assert.EqualError(t, err, "Character not in alphabet")
+
+	line, err = standardLine(&FileInfo{Encoding: UUEncoding}, []byte("#0V%T"), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "#0V%T", string(line))
+}
+
+func TestUuReader_table(t *testing.T) {
+	readers := []LineReader{
+		NewSliceLineReader([]byte(tableInput)),
+		NewReaderLineReader(bytes.NewReader([]byte(tableInput))),
+	}
+	for _, lr := range readers {
+		reader := NewReader(lr)
+		contents, err := ioutil.ReadAll(reader)
+		assert.Nil(t, err)
+		assert.Equal(t, "Cat", string(contents))
+
+		fileInfo, _ := reader.FileInfo()
+		assert.Equal(t, testAlphabet, fileInfo.Alphabet)
+		assert.Equal(t, int64(3), fileInfo.Size())
+	}
+}
+
+func TestUuReader_optionsAlphabet(t *testing.T) {
+	input := "begin 644 cat.txt\nDQ2F0\nA\nend\n"
+	reader := NewReaderWithOptions(NewSliceLineReader([]byte(input)), ReaderOptions{Alphabet: testAlphabet})
+	contents, err := ioutil.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "Cat", string(contents))
+
+	reader = NewReaderWithOptions(NewSliceLineReader([]byte(input)), ReaderOptions{Alphabet: "ABC"})
+	_, err = ioutil.ReadAll(reader)
+	assert.Equal(t, errInvalidAlphabet, err)
+}
+
+func TestDecodeParallel_optionsAlphabet(t *testing.T) {
+	input := "begin 644 cat.txt\nDQ2F0\nA\nend\n"
+	_, contents, err := DecodeParallelWithOptions([]byte(input), 2, ReaderOptions{Alphabet: testAlphabet})
+	assert.Nil(t, err)
+	assert.Equal(t, "Cat", string(contents))
+
+	_, _, err = DecodeParallelWithOptions([]byte(input), 2, ReaderOptions{Alphabet: "ABC"})
+	assert.Equal(t, errInvalidAlphabet, err)
+}
+
+func TestDecodingWriter_optionsAlphabet(t *testing.T) {
+	input := "begin 644 cat.txt\nDQ2F0\nA\nend\n"
+	var out bytes.Buffer
+	w := NewDecodingWriterWithOptions(&out, nil, ReaderOptions{Alphabet: testAlphabet})
+	_, err := w.Write([]byte(input))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+	assert.Equal(t, "Cat", out.String())
+
+	w = NewDecodingWriterWithOptions(&out, nil, ReaderOptions{Alphabet: "ABC"})
+	_, err = w.Write([]byte(input))
+	assert.Equal(t, errInvalidAlphabet, err)
+}
+
+func TestUuReader_invalidTable(t *testing.T) {
+	inputs := []string{
+		"begin 644 cat.txt\ntable\nABCDEFGHIJKLMNOPQRSTUVWXYZabcdef\nABCDEFGHIJKLMNOPQRSTUVWXYZabcdef\nDQ2F0\nA\nend\n",
+		"begin 644 cat.txt\ntable\nABCDEFGHIJKLMNOPQRSTUVWXYZabcdef\nghijklmnopqrstuvwxyz0123456789+-!\nDQ2F0\nA\nend\n",
+	}
+	for _, input := range inputs {
+		_, err := ioutil.ReadAll(NewReader(NewSliceLineReader([]byte(input))))
+		assert.Equal(t, errInvalidAlphabet, err)
+
+		_, _, err = DecodeParallel([]byte(input), 2)
+		assert.Equal(t, errInvalidAlphabet, err)
+
+		w := NewDecodingWriter(ioutil.Discard, nil)
+		_, err = w.Write([]byte(input))
+		assert.Equal(t, errInvalidAlphabet, err)
+	}
+}
+
+func TestWriter_alphabet(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, FileInfo{Encoding: UUEncoding, Mode: 0644, Name: "cat.txt", Alphabet: testAlphabet})
+	_, err := w.Write([]byte("Cat"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+	assert.Equal(t, tableInput, buf.String())
+
+	encoded, err := ioutil.ReadAll(NewEncodingReader(bytes.NewReader([]byte("Cat")), FileInfo{Encoding: UUEncoding, Mode: 0644, Name: "cat.txt", Alphabet: testAlphabet}))
+	assert.Nil(t, err)
+	assert.Equal(t, tableInput, string(encoded))
+
+	w = NewWriter(ioutil.Discard, FileInfo{Encoding: UUEncoding, Mode: 0644, Name: "cat.txt", Alphabet: "ABC"})
+	_, err = w.Write([]byte("Cat"))
+	assert.Equal(t, errInvalidAlphabet, err)
+}
+
+func TestAlphabet_roundTrip(t *testing.T) {
+	payload := testPayload(1000)
+	var buf bytes.Buffer
+	w := NewWriterWithOptions(&buf, FileInfo{Encoding: UUEncoding, Mode: 0644, Name: "test.bin", Alphabet: testAlphabet}, WriterOptions{LineChecksums: true})
+	_, err := w.Write(payload)
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+
+	contents, err := ioutil.ReadAll(NewReader(NewSliceLineReader(buf.Bytes())))
+	assert.Nil(t, err)
+	assert.Equal(t, payload, contents)
+
+	info, contents, err := DecodeParallel(buf.Bytes(), 4)
+	assert.Nil(t, err)
+	assert.Equal(t, payload, contents)
+	assert.Equal(t, testAlphabet, info.Alphabet)
+
+	var out bytes.Buffer
+	dw := NewDecodingWriter(&out, nil)
+	assert.Nil(t, writeInChunks(dw, buf.Bytes(), 7))
+	assert.Nil(t, dw.Close())
+	assert.Equal(t, payload, out.Bytes())
+}
@@ -0,0 +1,57 @@
+package uu
+
+import (
+	"io"
+)
+
+// The ArchiveWriter interface writes several encoded entries back to back into a single stream
+type ArchiveWriter interface {
+	// CreateEntry finishes the current entry, if any, and starts a new one. The returned io.Writer encodes the
+	// contents of the entry and is valid until the next call to CreateEntry or Close.
+	CreateEntry(info FileInfo) (io.Writer, error)
+	// Close finishes the current entry, if any. It does not close the underlying io.Writer.
+	Close() error
+}
+
+type archiveWriter struct {
+	writer  io.Writer
+	current io.WriteCloser
+	err     error
+}
+
+// NewArchiveWriter creates an ArchiveWriter writing to the provided io.Writer
+func NewArchiveWriter(writer io.Writer) ArchiveWriter {
+	return &archiveWriter{writer: writer}
+}
+
+func (a *archiveWriter) CreateEntry(info FileInfo) (io.Writer, error) {
+	if err := a.closeCurrent(); err != nil {
+		return nil, err
+	}
+	if err := checkEncoding(&info); err != nil {
+		return nil, err
+	}
+
+	a.current = NewWriter(a.writer, info)
+	return a.current, nil
+}
+
+func (a *archiveWriter) Close() error {
+	if err := a.closeCurrent(); err != nil {
+		return err
+	}
+
+	a.err = newError("Write on closed writer")
+	return nil
+}
+
+func (a *archiveWriter) closeCurrent() error {
+	if a.err != nil {
+		return a.err
+	}
+	if a.current != nil {
+		a.err = a.current.Close()
+		a.current = nil
+	}
+	return a.err
+}
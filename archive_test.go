@@ -0,0 +1,100 @@
+package uu
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"testing"
+)
+
+func TestArchiveWriter_writesEntries(t *testing.T) {
+	var out bytes.Buffer
+	a := NewArchiveWriter(&out)
+
+	w, err := a.CreateEntry(FileInfo{Name: "hello.txt", Mode: 0644})
+	assert.Nil(t, err)
+	_, err = w.Write([]byte("Hello World\n"))
+	assert.Nil(t, err)
+
+	w, err = a.CreateEntry(FileInfo{Encoding: Base64Encoding, Name: "cat.txt", Mode: 0600})
+	assert.Nil(t, err)
+	_, err = w.Write([]byte("Cat"))
+	assert.Nil(t, err)
+
+	_, err = a.CreateEntry(FileInfo{Name: "empty.txt", Mode: 0644})
+	assert.Nil(t, err)
+
+	assert.Nil(t, a.Close())
+	assert.Equal(t, "begin 644 hello.txt\n"+
+		",2&5L;&\\@5V]R;&0*\n"+
+		"`\n"+
+		"end\n"+
+		"begin-base64 600 cat.txt\n"+
+		"Q2F0\n"+
+		"====\n"+
+		"begin 644 empty.txt\n"+
+		"`\n"+
+		"end\n", out.String())
+}
+
+func TestArchiveWriter_roundTrip(t *testing.T) {
+	infos := []FileInfo{
+		{Encoding: UUEncoding, Name: "first.bin", Mode: 0644},
+		{Encoding: Base64Encoding, Name: "second.bin", Mode: 0755},
+		{Encoding: Base64Encoding, Name: "third.bin", Mode: 0600},
+		{Encoding: UUEncoding, Name: "fourth.bin", Mode: 0600},
+	}
+
+	var out bytes.Buffer
+	a := NewArchiveWriter(&out)
+	for i, info := range infos {
+		w, err := a.CreateEntry(info)
+		assert.Nil(t, err)
+		_, err = w.Write(testPayload(i * 100))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, a.Close())
+
+	reader := bufio.NewReader(&out)
+	for i, info := range infos {
+		r := NewReader(NewBufioLineReader(reader))
+		fileInfo, err := r.FileInfo()
+		assert.Nil(t, err)
+		assert.Equal(t, info.Encoding, fileInfo.Encoding)
+		assert.Equal(t, info.Name, fileInfo.Name)
+		assert.Equal(t, info.Mode, fileInfo.Mode)
+
+		contents, err := ioutil.ReadAll(r)
+		assert.Nil(t, err)
+		assert.Equal(t, testPayload(i*100), contents)
+	}
+}
+
+func TestArchiveWriter_invalidEncoding(t *testing.T) {
+	a := NewArchiveWriter(ioutil.Discard)
+
+	_, err := a.CreateEntry(FileInfo{Encoding: Encoding(7), Name: "hello.txt"})
+	assert.EqualError(t, err, "Invalid encoding")
+}
+
+func TestArchiveWriter_writeError(t *testing.T) {
+	a := NewArchiveWriter(&failingWriter{remaining: 30})
+
+	w, err := a.CreateEntry(FileInfo{Name: "hello.txt", Mode: 0644})
+	assert.Nil(t, err)
+	_, err = w.Write(testPayload(100))
+	assert.EqualError(t, err, "write failed")
+
+	_, err = a.CreateEntry(FileInfo{Name: "other.txt", Mode: 0644})
+	assert.EqualError(t, err, "write failed")
+	assert.EqualError(t, a.Close(), "write failed")
+}
+
+func TestArchiveWriter_createAfterClose(t *testing.T) {
+	a := NewArchiveWriter(ioutil.Discard)
+	assert.Nil(t, a.Close())
+
+	_, err := a.CreateEntry(FileInfo{Name: "hello.txt", Mode: 0644})
+	assert.EqualError(t, err, "Write on closed writer")
+}
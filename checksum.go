@@ -0,0 +1,157 @@
+package uu
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// sumLinePrefix starts the line some encoders write after the trailer, holding the BSD checksum (as computed by
+// sum -r) and the size of either the decoded file or the encoded section
+const sumLinePrefix = "sum -r/size "
+
+// ChecksumError is returned when the checksum of a payload line, or the checksum or size in a sum -r/size line
+// following the trailer, does not match the data
+type ChecksumError struct {
+	// Line is the line number within the entry, where the header is line 1
+	Line int
+}
+
+func (e *ChecksumError) Error() string {
+	return "Checksum mismatch on line " + strconv.Itoa(e.Line)
+}
+
+// An inputPeeker can return upcoming input without consuming it
+type inputPeeker interface {
+	peek(n int) []byte
+}
+
+// peeker returns reader as an inputPeeker if the innermost LineReader it wraps can return upcoming input, or nil
+// if it cannot. The LineReaders wrapping another one always have a peek method, returning nil if the one below
+// them cannot peek.
+func peeker(reader LineReader) inputPeeker {
+	inner := reader
+	for {
+		w, ok := inner.(lineReaderWrapper)
+		if !ok {
+			break
+		}
+		inner = w.unwrap()
+	}
+	if _, ok := inner.(inputPeeker); !ok {
+		return nil
+	}
+	p, _ := reader.(inputPeeker)
+	return p
+}
+
+// peekLine returns up to n bytes of upcoming input from reader, or nil if it cannot peek
+func peekLine(reader LineReader, n int) []byte {
+	if p, ok := reader.(inputPeeker); ok {
+		return p.peek(n)
+	}
+	return nil
+}
+
+// clip returns at most the first n bytes of b
+func clip(b []byte, n int) []byte {
+	if len(b) > n {
+		return b[:n]
+	}
+	return b
+}
+
+// bsdSum is the 16-bit rotating checksum computed by sum -r
+type bsdSum struct {
+	sum  uint16
+	size int64
+}
+
+func (s *bsdSum) Write(b []byte) (int, error) {
+	for _, c := range b {
+		s.sum = (s.sum>>1 | s.sum<<15) + uint16(c)
+	}
+	s.size += int64(len(b))
+	return len(b), nil
+}
+
+// lineChecksum is the checksum character of a payload line: the sum of the decoded bytes, modulo 64
+func lineChecksum(decoded []byte) byte {
+	var sum uint32
+	for _, c := range decoded {
+		sum += uint32(c)
+	}
+	return toEncoded(sum)
+}
+
+// hasLineChecksum reports whether a classic payload line has a checksum character following the payload
+func hasLineChecksum(fileInfo *FileInfo, in []byte, outLength int) bool {
+	if fileInfo.Encoding != UUEncoding {
+		return false
+	}
+	return len(bytes.TrimSuffix(in, []byte{'\r'})) == inLengthFromOutLength(outLength)+2
+}
+
+// checkLineChecksum verifies the checksum character of a payload line, if it has one
+func checkLineChecksum(fileInfo *FileInfo, in []byte, decoded []byte) bool {
+	if !hasLineChecksum(fileInfo, in, len(decoded)) {
+		return true
+	}
+	return fromEncoded(in[inLengthFromOutLength(len(decoded))+1]) == fromEncoded(lineChecksum(decoded))
+}
+
+// peekPrefix reports whether the LineReader can look ahead, and the next line starts with prefix
+func peekPrefix(reader LineReader, prefix string) bool {
+	p := peeker(reader)
+	return p != nil && bytes.HasPrefix(p.peek(len(prefix)), []byte(prefix))
+}
+
+// readSumLine consumes and verifies a sum -r/size line following the trailer, if the LineReader can look ahead
+// to find one. The line number is used for reporting a mismatch.
+func readSumLine(reader LineReader, number int, decoded *bsdSum, encoded *bsdSum) error {
+	if !peekPrefix(reader, sumLinePrefix) {
+		return nil
+	}
+	line, err := reader.ReadLine()
+	if err != nil {
+		return err
+	}
+	if !checkSumLine(line, decoded, encoded) {
+		return &ChecksumError{Line: number}
+	}
+	return nil
+}
+
+// checkSumLine verifies a sum -r/size line. A line describing a section is compared with the encoded text from
+// the header to the trailer, and any other line with the decoded data.
+func checkSumLine(in []byte, decoded *bsdSum, encoded *bsdSum) bool {
+	fields := bytes.Fields(bytes.TrimPrefix(in, []byte(sumLinePrefix)))
+	if len(fields) == 0 {
+		return false
+	}
+	values := bytes.SplitN(fields[0], []byte{'/'}, 2)
+	if len(values) != 2 {
+		return false
+	}
+	sum, err := strconv.ParseUint(string(values[0]), 10, 16)
+	if err != nil {
+		return false
+	}
+	size, err := strconv.ParseInt(string(values[1]), 10, 64)
+	if err != nil {
+		return false
+	}
+
+	expected := decoded
+	if len(fields) > 1 && bytes.Equal(fields[1], []byte("section")) {
+		expected = encoded
+	}
+	return uint16(sum) == expected.sum && size == expected.size
+}
+
+func formatSumLine(decoded *bsdSum, out []byte) []byte {
+	out = append(out, sumLinePrefix...)
+	out = strconv.AppendUint(out, uint64(decoded.sum), 10)
+	out = append(out, '/')
+	out = strconv.AppendInt(out, decoded.size, 10)
+	return append(out, " entire input file\n"...)
+}
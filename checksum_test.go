@@ -0,0 +1,160 @@
+package uu
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"hash"
+	"hash/crc32"
+	"io/ioutil"
+	"testing"
+)
+
+const checksummedInput = "begin 644 cat.txt\n" +
+	"#0V%T8\n" +
+	"`\n" +
+	"end\n" +
+	"sum -r/size 16565/3 entire input file\n"
+
+func TestBsdSum(t *testing.T) {
+	var sum bsdSum
+	sum.Write([]byte("Ca"))
+	sum.Write([]byte("t"))
+	assert.Equal(t, bsdSum{sum: 16565, size: 3}, sum)
+}
+
+func TestLineChecksum(t *testing.T) {
+	assert.Equal(t, byte('8'), lineChecksum([]byte("Cat")))
+	assert.Equal(t, byte('`'), lineChecksum(nil))
+}
+
+func TestUuReader_lineChecksums(t *testing.T) {
+	contents, err := ioutil.ReadAll(NewReader(NewSliceLineReader([]byte(checksummedInput))))
+	assert.Nil(t, err)
+	assert.Equal(t, "Cat", string(contents))
+}
+
+func TestUuReader_lineChecksumMismatch(t *testing.T) {
+	input := bytes.Replace([]byte(checksummedInput), []byte("#0V%T8"), []byte("#0V%T9"), 1)
+
+	_, err := ioutil.ReadAll(NewReaderWithOptions(NewSliceLineReader(input), ReaderOptions{VerifyLineChecksums: true}))
+	assert.Equal(t, &ChecksumError{Line: 2}, err)
+	assert.EqualError(t, err, "Checksum mismatch on line 2")
+}
+
+func TestUuReader_sumLineMismatch(t *testing.T) {
+	inputs := []string{
+		"sum -r/size 16566/3 entire input file\n",
+		"sum -r/size 16565/4 entire input file\n",
+		"sum -r/size 16565/3 section (from \"begin\" to \"end\")\n",
+		"sum -r/size garbage\n",
+	}
+	for _, sumLine := range inputs {
+		input := checksummedInput[:len(checksummedInput)-len("sum -r/size 16565/3 entire input file\n")] + sumLine
+
+		_, err := ioutil.ReadAll(NewReader(NewSliceLineReader([]byte(input))))
+		assert.Equal(t, &ChecksumError{Line: 5}, err, sumLine)
+	}
+}
+
+func TestUuReader_sectionSumLine(t *testing.T) {
+	section := "begin 644 cat.txt\n#0V%T\n`\nend\n"
+	var sum bsdSum
+	sum.Write([]byte(section))
+	input := section + string(formatSumLine(&sum, nil))
+	input = input[:len(input)-len("entire input file\n")] + "section (from \"begin\" to \"end\")\n"
+
+	contents, err := ioutil.ReadAll(NewReader(NewSliceLineReader([]byte(input))))
+	assert.Nil(t, err)
+	assert.Equal(t, "Cat", string(contents))
+}
+
+func TestWriterOptions_roundTrip(t *testing.T) {
+	payload := testPayload(1000)
+	var buf bytes.Buffer
+	w := NewWriterWithOptions(&buf, FileInfo{Encoding: UUEncoding, Mode: 0644, Name: "test.bin"}, WriterOptions{LineChecksums: true, SumTrailer: true})
+	_, err := w.Write(payload)
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+
+	lines := bytes.Split(buf.Bytes(), []byte{'\n'})
+	assert.Equal(t, 62, len(lines[1]))
+	assert.True(t, bytes.HasPrefix(lines[len(lines)-2], []byte("sum -r/size ")))
+
+	contents, err := ioutil.ReadAll(NewReaderWithOptions(NewSliceLineReader(buf.Bytes()), ReaderOptions{VerifyLineChecksums: true}))
+	assert.Nil(t, err)
+	assert.Equal(t, payload, contents)
+
+	_, contents, err = DecodeParallelWithOptions(buf.Bytes(), 4, ReaderOptions{VerifyLineChecksums: true})
+	assert.Nil(t, err)
+	assert.Equal(t, payload, contents)
+
+	var out bytes.Buffer
+	dw := NewDecodingWriterWithOptions(&out, nil, ReaderOptions{VerifyLineChecksums: true})
+	assert.Nil(t, writeInChunks(dw, buf.Bytes(), 7))
+	assert.Nil(t, dw.Close())
+	assert.Equal(t, payload, out.Bytes())
+}
+
+func TestDecodeParallel_checksumMismatch(t *testing.T) {
+	input := bytes.Replace([]byte(checksummedInput), []byte("#0V%T8"), []byte("#0V%T9"), 1)
+	_, _, err := DecodeParallelWithOptions(input, 2, ReaderOptions{VerifyLineChecksums: true})
+	assert.Equal(t, &ChecksumError{Line: 2}, err)
+
+	input = bytes.Replace([]byte(checksummedInput), []byte("16565"), []byte("16566"), 1)
+	_, _, err = DecodeParallel(input, 2)
+	assert.Equal(t, &ChecksumError{Line: 5}, err)
+}
+
+func TestDecodingWriter_checksumMismatch(t *testing.T) {
+	input := bytes.Replace([]byte(checksummedInput), []byte("#0V%T8"), []byte("#0V%T9"), 1)
+	w := NewDecodingWriterWithOptions(ioutil.Discard, nil, ReaderOptions{VerifyLineChecksums: true})
+	_, err := w.Write(input)
+	assert.Equal(t, &ChecksumError{Line: 2}, err)
+
+	input = bytes.Replace([]byte(checksummedInput), []byte("16565"), []byte("16566"), 1)
+	w = NewDecodingWriter(ioutil.Discard, nil)
+	_, err = w.Write(input)
+	assert.Equal(t, &ChecksumError{Line: 5}, err)
+}
+
+func TestLineChecksums_notVerifiedByDefault(t *testing.T) {
+	inputs := []string{
+		"begin 644 a\n#0V%T \n`\nend\n",
+		"begin 644 a\n#0V%T`\n`\nend\n",
+		"begin 644 a\n#0V%T9\n`\nend\n",
+	}
+	for _, input := range inputs {
+		contents, err := ioutil.ReadAll(NewReader(NewSliceLineReader([]byte(input))))
+		assert.Nil(t, err, input)
+		assert.Equal(t, "Cat", string(contents))
+
+		_, contents, err = DecodeParallel([]byte(input), 2)
+		assert.Nil(t, err, input)
+		assert.Equal(t, "Cat", string(contents))
+
+		var out bytes.Buffer
+		w := NewDecodingWriter(&out, nil)
+		_, err = w.Write([]byte(input))
+		assert.Nil(t, err, input)
+		assert.Nil(t, w.Close())
+		assert.Equal(t, "Cat", out.String())
+	}
+}
+
+func TestWithOptions_unsupportedOptions(t *testing.T) {
+	for _, options := range []ReaderOptions{
+		{AllowTruncated: true},
+		{Recovery: SkipRecovery},
+		{Hashes: map[string]func() hash.Hash{"crc32": func() hash.Hash { return crc32.NewIEEE() }}},
+	} {
+		_, _, err := DecodeParallelWithOptions([]byte(checksummedInput), 2, options)
+		assert.EqualError(t, err, "Unsupported ReaderOptions")
+
+		w := NewDecodingWriterWithOptions(ioutil.Discard, nil, options)
+		_, err = w.Write([]byte(checksummedInput))
+		assert.EqualError(t, err, "Unsupported ReaderOptions")
+	}
+
+	_, _, err := DecodeParallelWithOptions([]byte(checksummedInput), 2, ReaderOptions{VerifySize: true})
+	assert.EqualError(t, err, "Unsupported ReaderOptions")
+}
@@ -0,0 +1,96 @@
+// Command uuscan lists, and optionally extracts, the UU encoded attachments found in the plain-text bodies of the
+// messages in mbox files and Maildir directories.
+//
+// Usage:
+//
+//	uuscan [-x dir] mailbox...
+//
+// Each attachment is listed on a line with the message id, mode, decoded size and name, separated by tabs. With
+// -x, the attachments are also written to dir, using the base of their names. Existing files are not overwritten.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/gsson/uu"
+	"github.com/gsson/uu/mailbox"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	extract := flag.String("x", "", "extract the attachments into `dir`")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: uuscan [-x dir] mailbox...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	status := 0
+	for _, path := range flag.Args() {
+		if err := scan(path, *extract); err != nil {
+			fmt.Fprintf(os.Stderr, "uuscan: %s: %v\n", path, err)
+			status = 1
+		}
+	}
+	os.Exit(status)
+}
+
+func scan(path string, extract string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	var s *mailbox.Scanner
+	if fi.IsDir() {
+		s = mailbox.NewMaildirScanner(path, uu.ReaderOptions{})
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		s = mailbox.NewMboxScanner(f, uu.ReaderOptions{})
+	}
+
+	for s.Next() {
+		a := s.Attachment()
+		size, err := save(a, extract)
+		fmt.Printf("%s\t%s\t%d\t%s\n", a.MessageID, a.Info.Mode, size, a.Info.Name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "uuscan: %s: %s: %v\n", a.MessageID, a.Info.Name, err)
+		}
+	}
+	return s.Err()
+}
+
+// save decodes an attachment into the extraction directory, or discards it if there is none, and returns the
+// number of bytes decoded
+func save(a mailbox.Attachment, extract string) (int64, error) {
+	if extract == "" {
+		return io.Copy(ioutil.Discard, a.Reader)
+	}
+
+	base := filepath.Base(filepath.FromSlash(a.Info.Name))
+	if base == "." || base == ".." || base == string(filepath.Separator) {
+		n, _ := io.Copy(ioutil.Discard, a.Reader)
+		return n, fmt.Errorf("unsafe file name")
+	}
+	f, err := os.OpenFile(filepath.Join(extract, base), os.O_WRONLY|os.O_CREATE|os.O_EXCL, a.Info.Mode.Perm())
+	if err != nil {
+		n, _ := io.Copy(ioutil.Discard, a.Reader)
+		return n, err
+	}
+	n, err := io.Copy(f, a.Reader)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return n, err
+}
@@ -0,0 +1,151 @@
+package uu
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"github.com/gsson/uu/internal/unixmode"
+	"io"
+	"os"
+)
+
+// ToTar decodes every entry read from the LineReader into a file in the tar archive, with the name and mode of
+// its FileInfo. Names are made relative as by NewFS, and names leading outside the archive are rejected. It does
+// not close the tar.Writer.
+//
+// A tar header holds the size of the file, so the contents are streamed only if the size is known before decoding,
+// as when reading from a []byte slice. Otherwise each entry is held in memory while it is decoded.
+func ToTar(reader LineReader, tw *tar.Writer) error {
+	return convertEntries(reader, ReaderOptions{}, func(r *uuReader) error {
+		name, err := archivePath(r.info)
+		if err != nil {
+			return err
+		}
+		hdr := &tar.Header{
+			Name:     name,
+			Mode:     int64(unixmode.Bits(r.info.Mode)),
+			Typeflag: tar.TypeReg,
+		}
+
+		var contents io.Reader = r
+		if r.info.scanAhead(); r.info.estimated {
+			hdr.Size = r.info.estimate
+		} else {
+			var buf bytes.Buffer
+			if _, err := io.Copy(&buf, r); err != nil {
+				return err
+			}
+			hdr.Size, contents = int64(buf.Len()), &buf
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err = io.Copy(tw, contents)
+		return err
+	})
+}
+
+// ToZip decodes every entry read from the LineReader into a deflated file in the zip archive, with the name and
+// mode of its FileInfo. Names are handled as by ToTar. It does not close the zip.Writer.
+func ToZip(reader LineReader, zw *zip.Writer) error {
+	return convertEntries(reader, ReaderOptions{}, func(r *uuReader) error {
+		name, err := archivePath(r.info)
+		if err != nil {
+			return err
+		}
+		fh := &zip.FileHeader{Name: name, Method: zip.Deflate}
+		fh.SetMode(r.info.Mode)
+		w, err := zw.CreateHeader(fh)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(w, r)
+		return err
+	})
+}
+
+// archivePath returns the name of an entry as a path in a tar or zip archive
+func archivePath(info *FileInfo) (string, error) {
+	name, ok := entryPath(info.Name)
+	if !ok {
+		return "", newError("Invalid file name: " + info.Name)
+	}
+	return name, nil
+}
+
+func convertEntries(reader LineReader, options ReaderOptions, convert func(r *uuReader) error) error {
+	for {
+		r, err := readEntry(reader, options)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = convert(r); err != nil {
+			return err
+		}
+	}
+}
+
+// archiveMode keeps the permission bits of a mode from a tar or zip archive
+func archiveMode(mode os.FileMode) os.FileMode {
+	return mode & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+}
+
+// FromTar encodes the regular files of the tar archive as consecutive entries written to the provided io.Writer,
+// using the encoding given. Directories, links and other special files are skipped.
+func FromTar(tr *tar.Reader, writer io.Writer, encoding Encoding) error {
+	aw := NewArchiveWriter(writer)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return aw.Close()
+		}
+		if err != nil {
+			return err
+		}
+		if !hdr.FileInfo().Mode().IsRegular() {
+			continue
+		}
+
+		w, err := aw.CreateEntry(FileInfo{Encoding: encoding, Name: hdr.Name, Mode: unixmode.FileMode(uint32(hdr.Mode))})
+		if err != nil {
+			return err
+		}
+		if _, err = io.Copy(w, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// FromZip encodes the regular files of the zip archive as consecutive entries written to the provided io.Writer,
+// using the encoding given. Directories and other special files are skipped.
+func FromZip(zr *zip.Reader, writer io.Writer, encoding Encoding) error {
+	aw := NewArchiveWriter(writer)
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+
+		w, err := aw.CreateEntry(FileInfo{Encoding: encoding, Name: f.Name, Mode: archiveMode(f.Mode())})
+		if err != nil {
+			return err
+		}
+		if err = copyZipFile(w, f); err != nil {
+			return err
+		}
+	}
+	return aw.Close()
+}
+
+func copyZipFile(w io.Writer, f *zip.File) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	_, err = io.Copy(w, rc)
+	return err
+}
@@ -0,0 +1,134 @@
+package uu
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+const convertInput = "begin 644 hello.txt\n" +
+	",2&5L;&\\@5V]R;&0*\n" +
+	"`\n" +
+	"end\n" +
+	"begin-base64 4755 bin/cat\n" +
+	"Q2F0\n" +
+	"====\n"
+
+type convertedFile struct {
+	name     string
+	mode     os.FileMode
+	contents string
+}
+
+func readTar(t *testing.T, data []byte) []convertedFile {
+	var files []convertedFile
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			return files
+		}
+		contents, err := ioutil.ReadAll(tr)
+		assert.Nil(t, err)
+		files = append(files, convertedFile{hdr.Name, hdr.FileInfo().Mode(), string(contents)})
+	}
+}
+
+func TestToTar(t *testing.T) {
+	expected := []convertedFile{
+		{"hello.txt", 0644, "Hello World\n"},
+		{"bin/cat", 0755 | os.ModeSetuid, "Cat"},
+	}
+	readers := []LineReader{
+		NewSliceLineReader([]byte(convertInput)),
+		NewReaderLineReader(bytes.NewReader([]byte(convertInput))),
+	}
+	for _, lr := range readers {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		assert.Nil(t, ToTar(lr, tw))
+		assert.Nil(t, tw.Close())
+		assert.Equal(t, expected, readTar(t, buf.Bytes()))
+	}
+}
+
+func TestToZip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	assert.Nil(t, ToZip(NewSliceLineReader([]byte(convertInput)), zw))
+	assert.Nil(t, zw.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.Nil(t, err)
+	var files []convertedFile
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		contents, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		files = append(files, convertedFile{f.Name, f.Mode(), string(contents)})
+	}
+	assert.Equal(t, []convertedFile{
+		{"hello.txt", 0644, "Hello World\n"},
+		{"bin/cat", 0755 | os.ModeSetuid, "Cat"},
+	}, files)
+}
+
+func TestToTar_error(t *testing.T) {
+	tw := tar.NewWriter(ioutil.Discard)
+	err := ToTar(NewSliceLineReader([]byte("begin 644 cat.txt\n#0V%T\n")), tw)
+	assert.Equal(t, ErrTruncated, err)
+}
+
+func TestToTar_names(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	input := "begin 644 /abs/a\n#0V%T\n`\nend\nbegin 644 ./b/../c\n#0V%T\n`\nend\n"
+	assert.Nil(t, ToTar(NewSliceLineReader([]byte(input)), tw))
+	assert.Nil(t, tw.Close())
+	assert.Equal(t, []convertedFile{{"abs/a", 0644, "Cat"}, {"c", 0644, "Cat"}}, readTar(t, buf.Bytes()))
+}
+
+func TestToTar_unsafeNames(t *testing.T) {
+	for _, name := range []string{"../../etc/x", "a/../../x", "/", "."} {
+		input := "begin 644 " + name + "\n#0V%T\n`\nend\n"
+		err := ToTar(NewSliceLineReader([]byte(input)), tar.NewWriter(ioutil.Discard))
+		assert.EqualError(t, err, "Invalid file name: "+name)
+		err = ToZip(NewSliceLineReader([]byte(input)), zip.NewWriter(ioutil.Discard))
+		assert.EqualError(t, err, "Invalid file name: "+name)
+	}
+}
+
+func TestFromTar(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	tw.WriteHeader(&tar.Header{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0755})
+	tw.WriteHeader(&tar.Header{Name: "dir/hello.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 12})
+	tw.Write([]byte("Hello World\n"))
+	tw.WriteHeader(&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "dir/hello.txt"})
+	tw.Close()
+
+	var out bytes.Buffer
+	assert.Nil(t, FromTar(tar.NewReader(&buf), &out, UUEncoding))
+	assert.Equal(t, "begin 644 dir/hello.txt\n,2&5L;&\\@5V]R;&0*\n`\nend\n", out.String())
+}
+
+func TestFromZip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	zw.Create("dir/")
+	fh := &zip.FileHeader{Name: "dir/cat", Method: zip.Deflate}
+	fh.SetMode(0755 | os.ModeSetuid)
+	w, _ := zw.CreateHeader(fh)
+	w.Write([]byte("Cat"))
+	zw.Close()
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.Nil(t, err)
+	var out bytes.Buffer
+	assert.Nil(t, FromZip(zr, &out, Base64Encoding))
+	assert.Equal(t, "begin-base64 4755 dir/cat\nQ2F0\n====\n", out.String())
+}
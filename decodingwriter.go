@@ -0,0 +1,223 @@
+package uu
+
+import (
+	"bytes"
+	"io"
+)
+
+type decodingState int
+
+const (
+	expectBegin decodingState = iota
+	expectPayload
+	expectTable
+	expectEnd
+)
+
+type decodingWriter struct {
+	dst     io.Writer
+	onEntry func(*FileInfo) io.Writer
+	entry   io.Writer
+	info    *FileInfo
+	state   decodingState
+	pending []byte
+	scratch []byte
+	std     []byte
+	table   []byte
+	line    int
+	decoded bsdSum
+	encoded bsdSum
+	ended   *FileInfo
+	options ReaderOptions
+	err     error
+}
+
+// NewDecodingWriter creates an io.WriteCloser that decodes the UU encoded text written to it.
+//
+// The encoded text may be written in chunks of any size, and may contain several consecutive entries. When the
+// header of an entry has been read, onEntry is called with its FileInfo and the decoded contents of the entry
+// are written to the returned io.Writer. If onEntry is nil or returns nil, the contents are written to dst.
+//
+// Sum -r/size lines following the trailer of an entry are verified. Size lines are reported by DeclaredSize.
+//
+// Close must be called when all input has been written; it returns ErrTruncated if the input ended in the middle
+// of an entry.
+func NewDecodingWriter(dst io.Writer, onEntry func(*FileInfo) io.Writer) io.WriteCloser {
+	return NewDecodingWriterWithOptions(dst, onEntry, ReaderOptions{})
+}
+
+// NewDecodingWriterWithOptions creates an io.WriteCloser like NewDecodingWriter, configured by options. Only
+// VerifyLineChecksums, VerifySize and Alphabet are supported; if any other field of the ReaderOptions is set,
+// Write returns an error.
+func NewDecodingWriterWithOptions(dst io.Writer, onEntry func(*FileInfo) io.Writer, options ReaderOptions) io.WriteCloser {
+	w := &decodingWriter{dst: dst, onEntry: onEntry, options: options, scratch: make([]byte, 0, 45)}
+	if options.AllowTruncated || options.Recovery != NoRecovery || len(options.Hashes) > 0 {
+		w.err = errUnsupportedOptions
+	}
+	return w
+}
+
+func (w *decodingWriter) Write(b []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	n := 0
+	for {
+		i := bytes.IndexByte(b[n:], '\n')
+		if i == -1 {
+			break
+		}
+		line := b[n : n+i]
+		if len(w.pending) > 0 {
+			line = append(w.pending, line...)
+			w.pending = w.pending[:0]
+		}
+		if err := w.writeLine(line); err != nil {
+			w.err = err
+			return n, err
+		}
+		n += i + 1
+	}
+	w.pending = append(w.pending, b[n:]...)
+
+	return len(b), nil
+}
+
+func (w *decodingWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+
+	if len(w.pending) > 0 {
+		if err := w.writeLine(w.pending); err != nil {
+			if isShortLine(err) {
+				err = truncated(w.info, io.EOF)
+			}
+			w.err = err
+			return err
+		}
+		w.pending = nil
+	}
+
+	if w.state != expectBegin {
+		w.err = truncated(w.info, io.EOF)
+		return w.err
+	}
+
+	w.err = newError("Write on closed writer")
+	return nil
+}
+
+func (w *decodingWriter) writeLine(raw []byte) error {
+	if w.state != expectBegin {
+		w.line++
+		w.encoded.Write(raw)
+		w.encoded.Write([]byte{'\n'})
+	}
+	line := bytes.TrimSuffix(raw, []byte{'\r'})
+
+	switch w.state {
+	case expectBegin:
+		if len(bytes.TrimSpace(line)) == 0 {
+			return nil
+		}
+		if w.ended != nil && bytes.HasPrefix(line, []byte(sumLinePrefix)) {
+			w.line++
+			if !checkSumLine(line, &w.decoded, &w.encoded) {
+				return &ChecksumError{Line: w.line}
+			}
+			return nil
+		}
+		if w.ended != nil && bytes.HasPrefix(line, []byte(sizeLinePrefix)) {
+			w.line++
+			if !w.options.VerifySize {
+				if size, ok := parseSizeLine(line); ok {
+					w.ended.declared, w.ended.hasDeclared = size, true
+				}
+				return nil
+			}
+			return checkSizeLine(w.ended, line)
+		}
+		w.ended = nil
+		if isTrailerLine(line) {
+			// A stray size or sum -r/size line, skipped as readEntry does
+			return nil
+		}
+		info, err := parseBegin(line)
+		if err != nil {
+			return err
+		}
+		if info.Encoding == UUEncoding {
+			// Replaced by the alphabet of a table line following the header, if there is one
+			info.Alphabet = w.options.Alphabet
+			if _, err = info.alphabet(); err != nil {
+				return err
+			}
+		}
+		w.beginEntry(info, raw)
+	case expectPayload:
+		if w.line == 2 && w.info.Encoding == UUEncoding && isTableLine(line) {
+			w.table, w.state = w.table[:0], expectTable
+			return nil
+		}
+		line, err := standardLine(w.info, line, &w.std)
+		if err != nil {
+			return err
+		}
+		scratch, err := parsePayloadLine(w.info, line, w.scratch[:0])
+		if err == io.EOF {
+			if hasEndLine(w.info) {
+				w.state = expectEnd
+			} else {
+				w.endEntry()
+			}
+			return nil
+		}
+		if err == nil && w.options.VerifyLineChecksums && !checkLineChecksum(w.info, line, scratch) {
+			err = &ChecksumError{Line: w.line}
+		}
+		if err != nil {
+			return err
+		}
+		if _, err = w.entry.Write(scratch); err != nil {
+			return err
+		}
+		w.info.decoded += int64(len(scratch))
+		w.decoded.Write(scratch)
+	case expectTable:
+		w.table = append(w.table, line...)
+		if len(w.table) >= alphabetLength {
+			if _, err := newAlphabet(string(w.table)); err != nil {
+				return err
+			}
+			w.info.Alphabet, w.state = string(w.table), expectPayload
+		}
+	case expectEnd:
+		if err := parseEnd(w.info, line); err != nil {
+			return err
+		}
+		w.endEntry()
+	}
+	return nil
+}
+
+func (w *decodingWriter) endEntry() {
+	w.info.complete = true
+	w.ended, w.info, w.entry = w.info, nil, nil
+	w.state = expectBegin
+}
+
+func (w *decodingWriter) beginEntry(info *FileInfo, header []byte) {
+	w.info, w.entry = info, nil
+	w.line, w.decoded, w.encoded = 1, bsdSum{}, bsdSum{}
+	w.encoded.Write(header)
+	w.encoded.Write([]byte{'\n'})
+	if w.onEntry != nil {
+		w.entry = w.onEntry(info)
+	}
+	if w.entry == nil {
+		w.entry = w.dst
+	}
+	w.state = expectPayload
+}
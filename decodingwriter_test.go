@@ -0,0 +1,132 @@
+package uu
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+const multipleEntries = "begin 644 hello.txt\n" +
+	",2&5L;&\\@5V]R;&0*\n" +
+	"`\n" +
+	"end\n" +
+	"begin 600 cat.txt\r\n" +
+	"#0V%T\r\n" +
+	"`\r\n" +
+	"end\r\n"
+
+func writeInChunks(w io.Writer, data []byte, size int) error {
+	for len(data) > 0 {
+		n := size
+		if n > len(data) {
+			n = len(data)
+		}
+		if _, err := w.Write(data[:n]); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+func TestDecodingWriter_decodesFile(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/test.uu")
+	if err != nil {
+		panic(err)
+	}
+	expected, err := ioutil.ReadFile("testdata/test.bin")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, size := range []int{1, 2, 3, 7, 61, 62, 63, 4096} {
+		var out bytes.Buffer
+		var infos []*FileInfo
+		w := NewDecodingWriter(&out, func(info *FileInfo) io.Writer {
+			infos = append(infos, info)
+			return nil
+		})
+
+		assert.Nil(t, writeInChunks(w, data, size))
+		assert.Nil(t, w.Close())
+		assert.Equal(t, expected, out.Bytes())
+		assert.Equal(t, []*FileInfo{{Encoding: UUEncoding, Mode: 0644, Name: "test.bin", decoded: 1024, complete: true}}, infos)
+	}
+}
+
+func TestDecodingWriter_multipleEntries(t *testing.T) {
+	entries := make(map[string]*bytes.Buffer)
+	w := NewDecodingWriter(nil, func(info *FileInfo) io.Writer {
+		entries[info.Name] = &bytes.Buffer{}
+		return entries[info.Name]
+	})
+
+	assert.Nil(t, writeInChunks(w, []byte(multipleEntries), 5))
+	assert.Nil(t, w.Close())
+	assert.Equal(t, "Hello World\n", entries["hello.txt"].String())
+	assert.Equal(t, "Cat", entries["cat.txt"].String())
+}
+
+func TestDecodingWriter_blankLines(t *testing.T) {
+	var out bytes.Buffer
+	w := NewDecodingWriter(&out, nil)
+
+	assert.Nil(t, writeInChunks(w, []byte("\n \r\nbegin 644 a\n#0V%T\n`\nend\n\r\n\nbegin 644 b\n#0V%T\n`\nend\n\n"), 3))
+	assert.Nil(t, w.Close())
+	assert.Equal(t, "CatCat", out.String())
+}
+
+func TestDecodingWriter_unterminatedLastLine(t *testing.T) {
+	var out bytes.Buffer
+	w := NewDecodingWriter(&out, nil)
+
+	_, err := w.Write([]byte("begin 644 cat.txt\n#0V%T\n`\nend"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+	assert.Equal(t, "Cat", out.String())
+}
+
+func TestDecodingWriter_truncated(t *testing.T) {
+	inputs := []string{
+		"begin 644 cat.txt\n",
+		"begin 644 cat.txt\n#0V%T\n",
+		"begin 644 cat.txt\n#0V%T\n`\n",
+		"begin 644 cat.txt\n#0V",
+	}
+	for _, input := range inputs {
+		w := NewDecodingWriter(ioutil.Discard, nil)
+
+		_, err := w.Write([]byte(input))
+		assert.Nil(t, err)
+		assert.Equal(t, ErrTruncated, w.Close())
+	}
+}
+
+func TestDecodingWriter_invalidInput(t *testing.T) {
+	w := NewDecodingWriter(ioutil.Discard, nil)
+
+	n, err := w.Write([]byte("begin 644 cat.txt\n%0V%T\n`\nend\n"))
+	assert.Equal(t, 18, n)
+	assert.EqualError(t, err, "Input line too short")
+
+	_, err = w.Write([]byte("begin 644 cat.txt\n"))
+	assert.EqualError(t, err, "Input line too short")
+	assert.EqualError(t, w.Close(), "Input line too short")
+}
+
+func TestDecodingWriter_invalidHeader(t *testing.T) {
+	w := NewDecodingWriter(ioutil.Discard, nil)
+
+	_, err := w.Write([]byte("hello\n"))
+	assert.EqualError(t, err, "Invalid header")
+}
+
+func TestDecodingWriter_writeAfterClose(t *testing.T) {
+	w := NewDecodingWriter(ioutil.Discard, nil)
+	assert.Nil(t, w.Close())
+
+	_, err := w.Write([]byte("begin 644 cat.txt\n"))
+	assert.EqualError(t, err, "Write on closed writer")
+}
@@ -0,0 +1,113 @@
+package uu
+
+import (
+	"encoding/base64"
+	"github.com/gsson/uu/internal/unixmode"
+	"strconv"
+)
+
+// maxLineBytes is the number of decoded bytes in a full line
+const maxLineBytes = 45
+
+func toEncoded(v uint32) byte {
+	v &= 0x3f
+	if v == 0 {
+		return '`'
+	}
+	return byte(v) + ' '
+}
+
+func encode3to4(in []byte, out []byte) []byte {
+	combined := uint32(in[0])<<16 | uint32(in[1])<<8 | uint32(in[2])
+	return append(out,
+		toEncoded(combined>>18),
+		toEncoded(combined>>12),
+		toEncoded(combined>>6),
+		toEncoded(combined))
+}
+
+// encodePayloadLine appends an encoded line, not including the newline, to out
+func encodePayloadLine(fileInfo *FileInfo, in []byte, out []byte) []byte {
+	if fileInfo.Encoding == Base64Encoding {
+		start := len(out)
+		out = append(out, make([]byte, base64.StdEncoding.EncodedLen(len(in)))...)
+		base64.StdEncoding.Encode(out[start:], in)
+		return out
+	}
+	start := len(out)
+	out = encodeUULine(in, out)
+	fromStandard(fileInfo, out[start:])
+	return out
+}
+
+func encodeUULine(in []byte, out []byte) []byte {
+	out = append(out, toEncoded(uint32(len(in))))
+
+	var i int
+	for i = 0; i+3 <= len(in); i += 3 {
+		out = encode3to4(in[i:i+3], out)
+	}
+
+	var padded [3]byte
+	if copy(padded[:], in[i:]) > 0 {
+		out = encode3to4(padded[:], out)
+	}
+	return out
+}
+
+func formatMode(mode uint32) string {
+	formatted := strconv.FormatUint(uint64(mode), 8)
+	if len(formatted) < 3 {
+		return "000"[len(formatted):] + formatted
+	}
+	return formatted
+}
+
+var errInvalidEncoding = newError("Invalid encoding")
+
+// checkEncoding validates the encoding of an entry to be written
+func checkEncoding(fileInfo *FileInfo) error {
+	switch fileInfo.Encoding {
+	case UUEncoding, Base64Encoding, XXEncoding:
+		return nil
+	}
+	return errInvalidEncoding
+}
+
+// formatBegin appends the header line, including the newline, to out
+func formatBegin(fileInfo *FileInfo, out []byte) []byte {
+	switch fileInfo.Encoding {
+	case UUEncoding, XXEncoding:
+		out = append(out, "begin"...)
+	case Base64Encoding:
+		out = append(out, "begin-base64"...)
+	default:
+		panic("Invalid encoding")
+	}
+	if fileInfo.EncodedName {
+		out = append(out, "-encoded"...)
+	}
+	out = append(out, ' ')
+	out = append(out, formatMode(unixmode.Bits(fileInfo.Mode))...)
+	out = append(out, ' ')
+	if fileInfo.EncodedName {
+		out = append(out, base64.StdEncoding.EncodeToString([]byte(fileInfo.Name))...)
+	} else {
+		out = append(out, formatName(fileInfo.Name)...)
+	}
+	out = append(out, '\n')
+	if fileInfo.Encoding == UUEncoding && fileInfo.Alphabet != "" {
+		out = formatTable(fileInfo, out)
+	}
+	return out
+}
+
+// formatTrailer appends the terminating line and the trailer, including the newlines, to out
+func formatTrailer(fileInfo *FileInfo, out []byte) []byte {
+	if hasEndLine(fileInfo) {
+		out = append(out, toEncoded(0), '\n')
+		fromStandard(fileInfo, out[len(out)-2:len(out)-1])
+	}
+	out = append(out, endMarker(fileInfo)...)
+	return append(out, '\n')
+}
@@ -0,0 +1,75 @@
+package uu
+
+import (
+	"io"
+)
+
+type encodingReader struct {
+	src     io.Reader
+	info    FileInfo
+	in      []byte
+	out     []byte
+	scratch []byte
+	started bool
+	done    bool
+	err     error
+}
+
+// NewEncodingReader creates an io.Reader that yields the UU encoded form of the data read from src.
+//
+// The encoded text is produced on demand, one line at a time, and is identical to what NewWriter writes for
+// the same data.
+func NewEncodingReader(src io.Reader, info FileInfo) io.Reader {
+	return &encodingReader{
+		src:     src,
+		info:    info,
+		in:      make([]byte, maxLineBytes),
+		scratch: make([]byte, 0, lineLength),
+	}
+}
+
+func (r *encodingReader) Read(b []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.fill()
+	}
+
+	n := copy(b, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *encodingReader) fill() {
+	if !r.started {
+		r.started = true
+		if r.err = checkEncoding(&r.info); r.err != nil {
+			return
+		}
+		if _, r.err = r.info.alphabet(); r.err != nil {
+			return
+		}
+		r.out = formatBegin(&r.info, r.scratch[:0])
+		return
+	}
+	if r.done {
+		r.err = io.EOF
+		return
+	}
+
+	n, err := io.ReadFull(r.src, r.in)
+	switch err {
+	case nil:
+		r.out = append(encodePayloadLine(&r.info, r.in, r.scratch[:0]), '\n')
+	case io.ErrUnexpectedEOF:
+		r.out = append(encodePayloadLine(&r.info, r.in[:n], r.scratch[:0]), '\n')
+		r.out = formatTrailer(&r.info, r.out)
+		r.done = true
+	case io.EOF:
+		r.out = formatTrailer(&r.info, r.scratch[:0])
+		r.done = true
+	default:
+		r.err = err
+	}
+}
@@ -0,0 +1,66 @@
+package uu
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func TestEncodingReader_encodesFile(t *testing.T) {
+	expected, err := ioutil.ReadFile("testdata/test.uu")
+	if err != nil {
+		panic(err)
+	}
+	contents, err := ioutil.ReadFile("testdata/test.bin")
+	if err != nil {
+		panic(err)
+	}
+
+	r := NewEncodingReader(iotest.OneByteReader(bytes.NewReader(contents)), FileInfo{Name: "test.bin", Mode: 0644})
+	out, err := ioutil.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), string(out))
+}
+
+func TestEncodingReader_matchesWriter(t *testing.T) {
+	info := FileInfo{Name: "hello.txt", Mode: 0600}
+	for n := 0; n < 200; n++ {
+		var expected bytes.Buffer
+		w := NewWriter(&expected, info)
+		_, err := w.Write(testPayload(n))
+		assert.Nil(t, err)
+		assert.Nil(t, w.Close())
+
+		out, err := ioutil.ReadAll(iotest.OneByteReader(NewEncodingReader(bytes.NewReader(testPayload(n)), info)))
+		assert.Nil(t, err)
+		assert.Equal(t, expected.String(), string(out))
+	}
+}
+
+func TestEncodingReader_readError(t *testing.T) {
+	expected := newError("some error")
+	r := NewEncodingReader(errReader{expected}, FileInfo{Name: "hello.txt", Mode: 0644})
+
+	out, err := ioutil.ReadAll(r)
+	assert.Equal(t, "begin 644 hello.txt\n", string(out))
+	assert.Equal(t, expected, err)
+}
+
+func TestEncodingReader_invalidEncoding(t *testing.T) {
+	r := NewEncodingReader(strings.NewReader("hello"), FileInfo{Encoding: Encoding(-1), Name: "hello.txt", Mode: 0644})
+
+	out, err := ioutil.ReadAll(r)
+	assert.Empty(t, out)
+	assert.EqualError(t, err, "Invalid encoding")
+}
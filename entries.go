@@ -0,0 +1,59 @@
+//go:build go1.23
+// +build go1.23
+
+package uu
+
+import (
+	"io"
+	"iter"
+)
+
+// Entry is an entry yielded by Entries. It is only valid until the next iteration.
+type Entry struct {
+	// FileInfo describes the entry
+	FileInfo *FileInfo
+
+	reader *uuReader
+}
+
+var errStaleEntry = newError("Read from a stale entry")
+
+// Read reads the decoded contents of the entry
+func (e *Entry) Read(b []byte) (int, error) {
+	if e.reader == nil {
+		return 0, errStaleEntry
+	}
+	return e.reader.Read(b)
+}
+
+// Entries returns an iterator over the entries read from the LineReader, skipping blank lines between them.
+//
+// The rest of an entry is skipped when the iteration advances. An error reading a header, or skipping the rest of
+// an entry, is yielded with a nil Entry and ends the iteration. When the loop is left early, the LineReader is no
+// longer read, and is left where reading the current entry stopped.
+func Entries(reader LineReader) iter.Seq2[*Entry, error] {
+	return func(yield func(*Entry, error) bool) {
+		for {
+			r, err := readEntry(reader, ReaderOptions{})
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			entry := &Entry{FileInfo: r.info, reader: r}
+			more := yield(entry, nil)
+			entry.reader = nil
+			if !more {
+				return
+			}
+
+			if _, err = io.Copy(io.Discard, r); err != nil {
+				yield(nil, err)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+//go:build go1.23
+// +build go1.23
+
+package uu
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"testing"
+)
+
+const twoEntries = "begin 644 hello.txt\n" +
+	",2&5L;&\\@5V]R;&0*\n" +
+	"`\n" +
+	"end\n" +
+	"\n" +
+	"begin 600 cat.txt\n" +
+	"#0V%T\n" +
+	"`\n" +
+	"end\n"
+
+func TestEntries(t *testing.T) {
+	var names, contents []string
+	for e, err := range Entries(NewSliceLineReader([]byte(twoEntries))) {
+		assert.Nil(t, err)
+		data, err := ioutil.ReadAll(e)
+		assert.Nil(t, err)
+		names = append(names, e.FileInfo.Name)
+		contents = append(contents, string(data))
+	}
+	assert.Equal(t, []string{"hello.txt", "cat.txt"}, names)
+	assert.Equal(t, []string{"Hello World\n", "Cat"}, contents)
+}
+
+func TestEntries_skipsUnreadContents(t *testing.T) {
+	var entries []*Entry
+	for e, err := range Entries(NewSliceLineReader([]byte(twoEntries))) {
+		assert.Nil(t, err)
+		entries = append(entries, e)
+	}
+	assert.Equal(t, 2, len(entries))
+	assert.True(t, entries[0].FileInfo.Complete())
+
+	_, err := entries[0].Read(make([]byte, 1))
+	assert.Equal(t, errStaleEntry, err)
+}
+
+func TestEntries_break(t *testing.T) {
+	lr := NewSliceLineReader([]byte(twoEntries))
+	var stale *Entry
+	for e := range Entries(lr) {
+		stale = e
+		break
+	}
+	_, err := stale.Read(make([]byte, 1))
+	assert.Equal(t, errStaleEntry, err)
+
+	// The LineReader is left right after the header of the first entry
+	line, _ := lr.ReadLine()
+	assert.Equal(t, ",2&5L;&\\@5V]R;&0*", string(line))
+}
+
+func TestEntries_errors(t *testing.T) {
+	var errs []error
+	for e, err := range Entries(NewSliceLineReader([]byte("begin 644 cat.txt\n#0V%T\n`\nend\ngarbage\n"))) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		assert.Equal(t, "cat.txt", e.FileInfo.Name)
+	}
+	assert.Equal(t, []error{newError("Invalid header")}, errs)
+
+	errs = nil
+	for _, err := range Entries(NewSliceLineReader([]byte("begin 644 cat.txt\n#0V%T\n"))) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	assert.Equal(t, []error{ErrTruncated}, errs)
+}
@@ -0,0 +1,39 @@
+//go:build go1.23
+// +build go1.23
+
+package uu_test
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/gsson/uu"
+	"io/ioutil"
+)
+
+// Read multiple chunks with an iterator
+func ExampleEntries() {
+	input := "begin 644 hello.txt\n" +
+		",2&5L;&\\@5V]R;&0*\n" +
+		"`\n" +
+		"end\n" +
+		"begin 600 cat.txt\n" +
+		"#0V%T\n" +
+		"`\n" +
+		"end\n"
+	reader := bufio.NewReader(bytes.NewBufferString(input))
+
+	for entry, err := range uu.Entries(uu.NewBufioLineReader(reader)) {
+		if err != nil {
+			panic(err)
+		}
+		contents, err := ioutil.ReadAll(entry)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("%s: %q\n", entry.FileInfo.Name, contents)
+	}
+	// Output:
+	// hello.txt: "Hello World\n"
+	// cat.txt: "Cat"
+}
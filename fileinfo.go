@@ -0,0 +1,102 @@
+package uu
+
+import (
+	"os"
+	"path"
+	"time"
+)
+
+// Size returns the number of decoded bytes in the entry.
+//
+// The size is exact once the entry has been read to the end, as reported by Complete. Before that, it is the size
+// given by a size line following the trailer if known, as reported by DeclaredSize. Otherwise, if the LineReader
+// can look ahead without consuming input (as the one created by NewSliceLineReader), it is the size given by the
+// length characters of the remaining lines, and if not, the number of bytes decoded so far.
+func (fi *FileInfo) Size() int64 {
+	fi.scanAhead()
+	if fi.hasDeclared && !fi.complete {
+		return fi.declared
+	}
+	if fi.estimated && !fi.complete {
+		return fi.estimate
+	}
+	return fi.decoded
+}
+
+// Complete reports whether the entry has been read to the end, including the trailer
+func (fi *FileInfo) Complete() bool {
+	return fi.complete
+}
+
+// Truncated reports whether the input ended before the end of the entry
+func (fi *FileInfo) Truncated() bool {
+	return fi.truncated
+}
+
+// ModTime returns the zero time, as the encoded data has no modification time
+func (fi *FileInfo) ModTime() time.Time {
+	return time.Time{}
+}
+
+// IsDir returns false, as an entry is always a file
+func (fi *FileInfo) IsDir() bool {
+	return false
+}
+
+// Sys returns nil
+func (fi *FileInfo) Sys() interface{} {
+	return nil
+}
+
+// FileInfo returns an os.FileInfo for the entry, for use with packages such as archive/tar, archive/zip and
+// io/fs. The Name and Mode fields shadow the methods of the same name, so FileInfo cannot implement it directly.
+func (fi *FileInfo) FileInfo() os.FileInfo {
+	return fileInfoStat{fi}
+}
+
+type fileInfoStat struct {
+	*FileInfo
+}
+
+func (fi fileInfoStat) Name() string {
+	return path.Base(fi.FileInfo.Name)
+}
+
+func (fi fileInfoStat) Mode() os.FileMode {
+	return fi.FileInfo.Mode
+}
+
+// scanAhead estimates the size and looks for a size line using the look-ahead LineReader, if there is one. It
+// reads the rest of the entry, so it is only done once, when the size is first asked for.
+func (fi *FileInfo) scanAhead() {
+	ahead := fi.ahead
+	if ahead == nil {
+		return
+	}
+	fi.ahead = nil
+	fi.estimate, fi.estimated = estimateSize(fi, ahead), true
+	if hasEndLine(fi) {
+		ahead.ReadLine()
+	}
+	findSizeLine(fi, ahead)
+}
+
+// estimateSize sums the lengths of the payload lines up to the terminating line, or the first invalid one
+func estimateSize(info *FileInfo, reader LineReader) int64 {
+	var size int64
+	for {
+		line, err := reader.ReadLine()
+		if err != nil {
+			return size
+		}
+		line, err = standardLine(info, line, nil)
+		if err != nil {
+			return size
+		}
+		n, err := payloadLength(info, line)
+		if err != nil {
+			return size
+		}
+		size += int64(n)
+	}
+}
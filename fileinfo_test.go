@@ -0,0 +1,115 @@
+package uu
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestFileInfo_Size_estimatedFromSlice(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/test.uu")
+	if err != nil {
+		panic(err)
+	}
+
+	r := NewReader(NewSliceLineReader(data))
+	info, err := r.FileInfo()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1024), info.Size())
+	assert.False(t, info.Complete())
+
+	_, err = ioutil.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1024), info.Size())
+	assert.True(t, info.Complete())
+}
+
+func TestFileInfo_Size_estimatedOnDemand(t *testing.T) {
+	r := NewReader(NewSliceLineReader([]byte("begin 644 hello.txt\n#0V%T\n`\nend\nsize 3\n")))
+	info, err := r.FileInfo()
+	assert.Nil(t, err)
+	assert.False(t, info.estimated)
+	assert.False(t, info.hasDeclared)
+
+	assert.Equal(t, int64(3), info.Size())
+	assert.True(t, info.estimated)
+	assert.True(t, info.hasDeclared)
+	assert.Nil(t, info.ahead)
+}
+
+func TestFileInfo_DeclaredSize_afterReading(t *testing.T) {
+	r := NewReader(NewSliceLineReader([]byte("begin 644 hello.txt\n#0V%T\n`\nend\nsize 3\n")))
+	_, err := ioutil.ReadAll(r)
+	assert.Nil(t, err)
+
+	info, _ := r.FileInfo()
+	assert.False(t, info.estimated)
+	size, ok := info.DeclaredSize()
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), size)
+}
+
+func TestFileInfo_Size_countedWhileReading(t *testing.T) {
+	r := openTestData("test.uu")
+	info, err := r.FileInfo()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), info.Size())
+
+	_, err = r.Read(make([]byte, 10))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(45), info.Size())
+	assert.False(t, info.Complete())
+
+	_, err = ioutil.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1024), info.Size())
+	assert.True(t, info.Complete())
+}
+
+func TestFileInfo_Size_estimateStopsAtInvalidLine(t *testing.T) {
+	r := NewReader(NewSliceLineReader([]byte("begin 644 hello.txt\n#0V%T\nN\n#0V%T\n`\nend\n")))
+	info, err := r.FileInfo()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), info.Size())
+}
+
+func TestFileInfo_Size_incompleteTrailer(t *testing.T) {
+	r := NewReader(NewBufioLineReader(bufio.NewReader(bytes.NewBufferString("begin 644 hello.txt\n#0V%T\n`\n"))))
+	contents, err := ioutil.ReadAll(r)
+	assert.Equal(t, ErrTruncated, err)
+	assert.Equal(t, "Cat", string(contents))
+
+	info, _ := r.FileInfo()
+	assert.Equal(t, int64(3), info.Size())
+	assert.False(t, info.Complete())
+	assert.True(t, info.Truncated())
+}
+
+func TestFileInfo_FileInfo(t *testing.T) {
+	info := &FileInfo{Name: "docs/hello.txt", Mode: 0640, decoded: 12, complete: true}
+	fi := info.FileInfo()
+
+	assert.Equal(t, "hello.txt", fi.Name())
+	assert.Equal(t, os.FileMode(0640), fi.Mode())
+	assert.Equal(t, int64(12), fi.Size())
+	assert.False(t, fi.IsDir())
+	assert.True(t, fi.ModTime().IsZero())
+	assert.Nil(t, fi.Sys())
+
+	th, err := tar.FileInfoHeader(fi, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "hello.txt", th.Name)
+	assert.Equal(t, int64(0640), th.Mode)
+	assert.Equal(t, int64(12), th.Size)
+
+	zh, err := zip.FileInfoHeader(fi)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello.txt", zh.Name)
+	assert.Equal(t, uint64(12), zh.UncompressedSize64)
+	assert.Equal(t, os.FileMode(0640), zh.Mode())
+}
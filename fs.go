@@ -0,0 +1,270 @@
+//go:build go1.16
+// +build go1.16
+
+package uu
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The FS interface exposes the entries of an encoded archive as a read-only file system
+type FS interface {
+	fs.ReadDirFS
+	fs.ReadFileFS
+	fs.StatFS
+}
+
+type fsNode struct {
+	name     string
+	info     *FileInfo
+	data     []byte
+	children map[string]*fsNode
+}
+
+type uuFS struct {
+	root *fsNode
+}
+
+// NewFS decodes every entry read from the LineReader and returns them as a file system.
+//
+// The FileInfo.Name of each entry is used as its path, with slashes separating directories, and FileInfo.Mode
+// provides its permissions. Leading slashes and "./" are removed from the names, and names that would refer
+// outside the root are rejected. If several entries have the same name, the last one is used.
+func NewFS(reader LineReader) (FS, error) {
+	root := &fsNode{name: ".", children: make(map[string]*fsNode)}
+	for {
+		r, err := readEntry(reader, ReaderOptions{})
+		if err == io.EOF {
+			return &uuFS{root: root}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		if err = root.add(r.info, data); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (n *fsNode) add(info *FileInfo, data []byte) error {
+	name, ok := entryPath(info.Name)
+	if !ok {
+		return newError("Invalid file name: " + info.Name)
+	}
+
+	elems := strings.Split(name, "/")
+	dir := n
+	for _, elem := range elems[:len(elems)-1] {
+		child, ok := dir.children[elem]
+		if !ok {
+			child = &fsNode{name: elem, children: make(map[string]*fsNode)}
+			dir.children[elem] = child
+		}
+		if !child.isDir() {
+			return newError("File used as directory: " + info.Name)
+		}
+		dir = child
+	}
+
+	base := elems[len(elems)-1]
+	if existing, ok := dir.children[base]; ok && existing.isDir() {
+		return newError("Directory used as file: " + info.Name)
+	}
+	dir.children[base] = &fsNode{name: base, info: info, data: data}
+	return nil
+}
+
+func (n *fsNode) isDir() bool {
+	return n.children != nil
+}
+
+func (n *fsNode) lookup(name string) *fsNode {
+	if name == "." {
+		return n
+	}
+	node := n
+	for _, elem := range strings.Split(name, "/") {
+		node = node.children[elem]
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+func (n *fsNode) entries() []fs.DirEntry {
+	entries := make([]fs.DirEntry, 0, len(n.children))
+	for _, child := range n.children {
+		entries = append(entries, &fsDirEntry{child})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries
+}
+
+func (f *uuFS) find(op string, name string) (*fsNode, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	node := f.root.lookup(name)
+	if node == nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return node, nil
+}
+
+func (f *uuFS) Open(name string) (fs.File, error) {
+	node, err := f.find("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if node.isDir() {
+		return &fsDir{node: node, path: name, entries: node.entries()}, nil
+	}
+	return &fsFile{node: node, Reader: bytes.NewReader(node.data)}, nil
+}
+
+func (f *uuFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	node, err := f.find("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !node.isDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: newError("not a directory")}
+	}
+	return node.entries(), nil
+}
+
+func (f *uuFS) ReadFile(name string) ([]byte, error) {
+	node, err := f.find("read", name)
+	if err != nil {
+		return nil, err
+	}
+	if node.isDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: newError("is a directory")}
+	}
+	return append([]byte(nil), node.data...), nil
+}
+
+func (f *uuFS) Stat(name string) (fs.FileInfo, error) {
+	node, err := f.find("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return &fsStat{node}, nil
+}
+
+type fsStat struct {
+	node *fsNode
+}
+
+func (s *fsStat) Name() string {
+	return s.node.name
+}
+
+func (s *fsStat) Size() int64 {
+	return int64(len(s.node.data))
+}
+
+func (s *fsStat) Mode() fs.FileMode {
+	if s.node.isDir() {
+		return fs.ModeDir | 0555
+	}
+	return s.node.info.Mode &^ fs.ModeType
+}
+
+func (s *fsStat) ModTime() time.Time {
+	return time.Time{}
+}
+
+func (s *fsStat) IsDir() bool {
+	return s.node.isDir()
+}
+
+// Sys returns the *FileInfo of a file, and nil for a directory
+func (s *fsStat) Sys() interface{} {
+	if s.node.info == nil {
+		return nil
+	}
+	return s.node.info
+}
+
+type fsDirEntry struct {
+	node *fsNode
+}
+
+func (e *fsDirEntry) Name() string {
+	return e.node.name
+}
+
+func (e *fsDirEntry) IsDir() bool {
+	return e.node.isDir()
+}
+
+func (e *fsDirEntry) Type() fs.FileMode {
+	return (&fsStat{e.node}).Mode().Type()
+}
+
+func (e *fsDirEntry) Info() (fs.FileInfo, error) {
+	return &fsStat{e.node}, nil
+}
+
+type fsFile struct {
+	*bytes.Reader
+	node *fsNode
+}
+
+func (f *fsFile) Stat() (fs.FileInfo, error) {
+	return &fsStat{f.node}, nil
+}
+
+func (f *fsFile) Close() error {
+	return nil
+}
+
+type fsDir struct {
+	node    *fsNode
+	path    string
+	entries []fs.DirEntry
+}
+
+func (d *fsDir) Stat() (fs.FileInfo, error) {
+	return &fsStat{d.node}, nil
+}
+
+func (d *fsDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.path, Err: newError("is a directory")}
+}
+
+func (d *fsDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
+
+func (d *fsDir) Close() error {
+	return nil
+}
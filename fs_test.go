@@ -0,0 +1,130 @@
+//go:build go1.16
+// +build go1.16
+
+package uu
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/fs"
+	"io/ioutil"
+	"testing"
+	"testing/fstest"
+)
+
+func newTestArchive(entries ...interface{}) []byte {
+	var out bytes.Buffer
+	a := NewArchiveWriter(&out)
+	for i := 0; i < len(entries); i += 2 {
+		w, err := a.CreateEntry(entries[i].(FileInfo))
+		if err != nil {
+			panic(err)
+		}
+		if _, err = w.Write([]byte(entries[i+1].(string))); err != nil {
+			panic(err)
+		}
+	}
+	if err := a.Close(); err != nil {
+		panic(err)
+	}
+	return out.Bytes()
+}
+
+func TestFS(t *testing.T) {
+	archive := newTestArchive(
+		FileInfo{Name: "hello.txt", Mode: 0644}, "Hello World\n",
+		FileInfo{Name: "docs/readme.md", Mode: 0600}, "# Readme\n",
+		FileInfo{Encoding: Base64Encoding, Name: "/docs/img/cat.gif", Mode: 0644}, "GIF89a",
+		FileInfo{Name: "./bin/tool", Mode: 0755}, "#!/bin/sh\n",
+	)
+
+	fsys, err := NewFS(NewSliceLineReader(archive))
+	assert.Nil(t, err)
+	assert.Nil(t, fstest.TestFS(fsys, "hello.txt", "docs/readme.md", "docs/img/cat.gif", "bin/tool"))
+
+	contents, err := fsys.ReadFile("docs/img/cat.gif")
+	assert.Nil(t, err)
+	assert.Equal(t, "GIF89a", string(contents))
+
+	info, err := fsys.Stat("bin/tool")
+	assert.Nil(t, err)
+	assert.Equal(t, fs.FileMode(0755), info.Mode())
+	assert.Equal(t, int64(10), info.Size())
+	assert.Equal(t, "./bin/tool", info.Sys().(*FileInfo).Name)
+
+	info, err = fsys.Stat("docs")
+	assert.Nil(t, err)
+	assert.True(t, info.IsDir())
+
+	entries, err := fsys.ReadDir(".")
+	assert.Nil(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{"bin", "docs", "hello.txt"}, names)
+}
+
+func TestFS_decodesFile(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/test.uu")
+	if err != nil {
+		panic(err)
+	}
+	expected, err := ioutil.ReadFile("testdata/test.bin")
+	if err != nil {
+		panic(err)
+	}
+
+	fsys, err := NewFS(NewSliceLineReader(data))
+	assert.Nil(t, err)
+
+	contents, err := fs.ReadFile(fsys, "test.bin")
+	assert.Nil(t, err)
+	assert.Equal(t, expected, contents)
+}
+
+func TestFS_replacesDuplicates(t *testing.T) {
+	archive := newTestArchive(
+		FileInfo{Name: "hello.txt", Mode: 0644}, "first",
+		FileInfo{Name: "hello.txt", Mode: 0600}, "second",
+	)
+
+	fsys, err := NewFS(NewSliceLineReader(archive))
+	assert.Nil(t, err)
+
+	contents, err := fsys.ReadFile("hello.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "second", string(contents))
+}
+
+func TestFS_notFound(t *testing.T) {
+	fsys, err := NewFS(NewSliceLineReader(newTestArchive(FileInfo{Name: "hello.txt", Mode: 0644}, "")))
+	assert.Nil(t, err)
+
+	_, err = fsys.Open("missing.txt")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+
+	_, err = fsys.Open("../hello.txt")
+	assert.True(t, errors.Is(err, fs.ErrInvalid))
+
+	_, err = fsys.ReadDir("hello.txt")
+	assert.EqualError(t, err, "readdir hello.txt: not a directory")
+}
+
+func TestFS_invalidNames(t *testing.T) {
+	assertFSFails(t, "Invalid file name: ../etc/passwd", FileInfo{Name: "../etc/passwd"}, "")
+	assertFSFails(t, "Invalid file name: /", FileInfo{Name: "/"}, "")
+	assertFSFails(t, "File used as directory: a/b", FileInfo{Name: "a"}, "", FileInfo{Name: "a/b"}, "")
+	assertFSFails(t, "Directory used as file: a", FileInfo{Name: "a/b"}, "", FileInfo{Name: "a"}, "")
+}
+
+func TestFS_invalidInput(t *testing.T) {
+	_, err := NewFS(NewSliceLineReader([]byte("begin 644 hello.txt\nN\n`\nend\n")))
+	assert.EqualError(t, err, "Invalid line length byte")
+}
+
+func assertFSFails(t *testing.T, message string, entries ...interface{}) {
+	_, err := NewFS(NewSliceLineReader(newTestArchive(entries...)))
+	assert.EqualError(t, err, message)
+}
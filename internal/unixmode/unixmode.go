@@ -0,0 +1,46 @@
+// Package unixmode converts between the octal Unix mode bits found in headers and shell commands, and os.FileMode.
+package unixmode
+
+import (
+	"os"
+)
+
+// Unix mode bits
+const (
+	Setuid = 04000
+	Setgid = 02000
+	Sticky = 01000
+	Perm   = 00777
+	// Mask holds all the bits that are converted
+	Mask = Setuid | Setgid | Sticky | Perm
+)
+
+// FileMode converts Unix mode bits to an os.FileMode. Bits outside Mask are ignored.
+func FileMode(v uint32) os.FileMode {
+	m := os.FileMode(v & Perm)
+	if v&Setuid != 0 {
+		m |= os.ModeSetuid
+	}
+	if v&Setgid != 0 {
+		m |= os.ModeSetgid
+	}
+	if v&Sticky != 0 {
+		m |= os.ModeSticky
+	}
+	return m
+}
+
+// Bits converts the permission, setuid, setgid and sticky bits of an os.FileMode to Unix mode bits
+func Bits(m os.FileMode) uint32 {
+	v := uint32(m.Perm())
+	if m&os.ModeSetuid != 0 {
+		v |= Setuid
+	}
+	if m&os.ModeSetgid != 0 {
+		v |= Setgid
+	}
+	if m&os.ModeSticky != 0 {
+		v |= Sticky
+	}
+	return v
+}
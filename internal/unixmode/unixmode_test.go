@@ -0,0 +1,19 @@
+package unixmode
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestFileMode(t *testing.T) {
+	assert.Equal(t, os.FileMode(0644), FileMode(0644))
+	assert.Equal(t, os.ModeSetuid|os.ModeSetgid|os.ModeSticky|0755, FileMode(07755))
+	assert.Equal(t, os.FileMode(0644), FileMode(0100644))
+}
+
+func TestBits(t *testing.T) {
+	assert.Equal(t, uint32(0644), Bits(0644))
+	assert.Equal(t, uint32(07755), Bits(os.ModeSetuid|os.ModeSetgid|os.ModeSticky|0755))
+	assert.Equal(t, uint32(0755), Bits(os.ModeDir|0755))
+}
@@ -0,0 +1,144 @@
+package uu
+
+import (
+	"strconv"
+)
+
+// Limits restricts the resources used when decoding untrusted input. A zero value for any of the limits means
+// that it is not enforced.
+type Limits struct {
+	// MaxLineLength is the maximum length of a line, not including the newline
+	MaxLineLength int
+	// MaxEntrySize is the maximum number of decoded bytes in a single entry
+	MaxEntrySize int64
+	// MaxTotalSize is the maximum number of decoded bytes in all entries
+	MaxTotalSize int64
+	// MaxEntries is the maximum number of entries
+	MaxEntries int
+	// MaxNameLength is the maximum length of the file name of an entry, in bytes
+	MaxNameLength int
+}
+
+// LimitError is returned when the input exceeds one of the Limits
+type LimitError struct {
+	// Limit is the name of the exceeded field of Limits, such as "MaxLineLength"
+	Limit string
+	// Max is the configured value of the limit
+	Max int64
+}
+
+func (e *LimitError) Error() string {
+	return "Limit exceeded: " + e.Limit + " " + strconv.FormatInt(e.Max, 10)
+}
+
+// A lineLimiter is implemented by the LineReaders that can stop buffering a line as soon as it grows too long
+type lineLimiter interface {
+	setMaxLineLength(max int)
+}
+
+// A lineReaderWrapper is implemented by the LineReaders that wrap another LineReader, so that a limitedLineReader,
+// and whether the input can be peeked at, can be found below them
+type lineReaderWrapper interface {
+	unwrap() LineReader
+}
+
+// findLimiter returns the limitedLineReader that reader is, or wraps, or nil if there is none
+func findLimiter(reader LineReader) *limitedLineReader {
+	for {
+		switch r := reader.(type) {
+		case *limitedLineReader:
+			return r
+		case lineReaderWrapper:
+			reader = r.unwrap()
+		default:
+			return nil
+		}
+	}
+}
+
+type limitedLineReader struct {
+	reader  LineReader
+	limits  Limits
+	entries int
+	entry   int64
+	total   int64
+	err     error
+}
+
+// NewLimitedLineReader creates a LineReader that enforces the provided Limits on the lines read from reader, and on
+// the entries decoded from it by the Readers created with NewReader. The limits span all entries read through the
+// returned LineReader.
+//
+// The LineReaders of this package stop reading a line as soon as it exceeds MaxLineLength. Readers return a
+// *LimitError before returning the bytes of a line that would exceed MaxEntrySize or MaxTotalSize, and when
+// reading the header of an entry that exceeds MaxEntries or MaxNameLength. The limits still apply when the
+// returned LineReader is wrapped by the other LineReaders of this package, such as NewDotUnstuffingLineReader.
+func NewLimitedLineReader(reader LineReader, limits Limits) LineReader {
+	if l, ok := reader.(lineLimiter); ok && limits.MaxLineLength > 0 {
+		l.setMaxLineLength(limits.MaxLineLength)
+	}
+	return &limitedLineReader{reader: reader, limits: limits}
+}
+
+func (r *limitedLineReader) ReadLine() ([]byte, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	line, err := r.reader.ReadLine()
+	if err != nil {
+		return nil, err
+	}
+	if r.limits.MaxLineLength > 0 && len(line) > r.limits.MaxLineLength {
+		return nil, r.fail("MaxLineLength", int64(r.limits.MaxLineLength))
+	}
+	return line, nil
+}
+
+func (r *limitedLineReader) unwrap() LineReader {
+	return r.reader
+}
+
+func (r *limitedLineReader) peek(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	return peekLine(r.reader, n)
+}
+
+func (r *limitedLineReader) beginEntry(info *FileInfo) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	r.entries++
+	r.entry = 0
+	if r.limits.MaxEntries > 0 && r.entries > r.limits.MaxEntries {
+		return r.fail("MaxEntries", int64(r.limits.MaxEntries))
+	}
+	if r.limits.MaxNameLength > 0 && len(info.Name) > r.limits.MaxNameLength {
+		return r.fail("MaxNameLength", int64(r.limits.MaxNameLength))
+	}
+	return nil
+}
+
+func (r *limitedLineReader) decoded(n int) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	r.entry += int64(n)
+	r.total += int64(n)
+	if r.limits.MaxEntrySize > 0 && r.entry > r.limits.MaxEntrySize {
+		return r.fail("MaxEntrySize", r.limits.MaxEntrySize)
+	}
+	if r.limits.MaxTotalSize > 0 && r.total > r.limits.MaxTotalSize {
+		return r.fail("MaxTotalSize", r.limits.MaxTotalSize)
+	}
+	return nil
+}
+
+func (r *limitedLineReader) fail(limit string, max int64) error {
+	r.err = &LimitError{Limit: limit, Max: max}
+	return r.err
+}
@@ -0,0 +1,105 @@
+package uu
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+const limitsInput = "begin 644 hello.txt\n" +
+	",2&5L;&\\@5V]R;&0*\n" +
+	"`\n" +
+	"end\n" +
+	"begin 644 cat.txt\n" +
+	"#0V%T\n" +
+	"`\n" +
+	"end\n"
+
+type countingReader struct {
+	reader io.Reader
+	read   int
+}
+
+func (r *countingReader) Read(b []byte) (int, error) {
+	n, err := r.reader.Read(b)
+	r.read += n
+	return n, err
+}
+
+func readAllEntries(reader LineReader) ([]string, error) {
+	var contents []string
+	for {
+		r, err := readEntry(reader, ReaderOptions{})
+		if err == io.EOF {
+			return contents, nil
+		}
+		if err != nil {
+			return contents, err
+		}
+		c, err := ioutil.ReadAll(r)
+		if err != nil {
+			return contents, err
+		}
+		contents = append(contents, string(c))
+	}
+}
+
+func TestLimitedLineReader_withinLimits(t *testing.T) {
+	limits := Limits{MaxLineLength: 19, MaxEntrySize: 12, MaxTotalSize: 15, MaxEntries: 2, MaxNameLength: 9}
+	contents, err := readAllEntries(NewLimitedLineReader(NewSliceLineReader([]byte(limitsInput)), limits))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Hello World\n", "Cat"}, contents)
+}
+
+func TestLimitedLineReader_limits(t *testing.T) {
+	assertLimitExceeded(t, Limits{MaxLineLength: 18}, "MaxLineLength", 18, nil)
+	assertLimitExceeded(t, Limits{MaxEntrySize: 11}, "MaxEntrySize", 11, nil)
+	assertLimitExceeded(t, Limits{MaxTotalSize: 14}, "MaxTotalSize", 14, []string{"Hello World\n"})
+	assertLimitExceeded(t, Limits{MaxEntries: 1}, "MaxEntries", 1, []string{"Hello World\n"})
+	assertLimitExceeded(t, Limits{MaxNameLength: 8}, "MaxNameLength", 8, nil)
+}
+
+func TestLimitedLineReader_wrapped(t *testing.T) {
+	limited := NewLimitedLineReader(NewSliceLineReader([]byte("begin 644 a\n#0V%T\n`\nend\n")), Limits{MaxEntrySize: 1})
+	r := NewReader(NewQuoteDetectingLineReader(NewFromUnmanglingLineReader(NewDotUnstuffingLineReader(limited))))
+	contents, err := ioutil.ReadAll(r)
+
+	assert.Empty(t, contents)
+	assert.Equal(t, &LimitError{Limit: "MaxEntrySize", Max: 1}, err)
+}
+
+func TestLimitedLineReader_stopsReadingLongLines(t *testing.T) {
+	input := "begin 644 hello.txt\n" + strings.Repeat("M", 1<<20) + "\n"
+
+	for _, newLineReader := range []func(io.Reader) LineReader{
+		func(r io.Reader) LineReader { return NewReaderLineReader(r) },
+		func(r io.Reader) LineReader { return NewByteReaderLineReader(bufio.NewReaderSize(r, 16)) },
+		func(r io.Reader) LineReader { return NewBufioLineReader(bufio.NewReaderSize(r, 16)) },
+	} {
+		counter := &countingReader{reader: bytes.NewBufferString(input)}
+		reader := NewLimitedLineReader(newLineReader(counter), Limits{MaxLineLength: 100})
+
+		_, err := ioutil.ReadAll(NewReader(reader))
+		assert.Equal(t, &LimitError{Limit: "MaxLineLength", Max: 100}, err)
+		assert.True(t, counter.read < 200, "read %d bytes", counter.read)
+
+		_, err = reader.ReadLine()
+		assert.Equal(t, &LimitError{Limit: "MaxLineLength", Max: 100}, err)
+	}
+}
+
+func TestLimitError_Error(t *testing.T) {
+	assert.EqualError(t, &LimitError{Limit: "MaxEntries", Max: 10}, "Limit exceeded: MaxEntries 10")
+}
+
+func assertLimitExceeded(t *testing.T, limits Limits, limit string, max int64, expected []string) {
+	contents, err := readAllEntries(NewLimitedLineReader(NewSliceLineReader([]byte(limitsInput)), limits))
+
+	assert.Equal(t, &LimitError{Limit: limit, Max: max}, err)
+	assert.Equal(t, expected, contents)
+}
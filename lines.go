@@ -13,24 +13,32 @@ type LineReader interface {
 	ReadLine() ([]byte, error)
 }
 
+// A lookAheadLineReader can provide the remaining lines without consuming them
+type lookAheadLineReader interface {
+	lookAhead() LineReader
+}
+
 type sliceLineReader struct {
 	remaining []byte
 }
 
 type byteReaderLineReader struct {
-	reader io.ByteReader
-	err    error
+	reader    io.ByteReader
+	err       error
+	maxLength int
 }
 
 type readerLineReader struct {
-	reader  io.Reader
-	err     error
-	scratch []byte
+	reader    io.Reader
+	err       error
+	scratch   []byte
+	maxLength int
 }
 
 type bufioLineReader struct {
-	reader *bufio.Reader
-	err    error
+	reader    *bufio.Reader
+	err       error
+	maxLength int
 }
 
 // NewSliceLineReader creates a LineReader for reading lines from a []byte slice
@@ -41,6 +49,37 @@ func NewSliceLineReader(bytes []byte) LineReader {
 	return &sliceLineReader{remaining: bytes}
 }
 
+func (r *byteReaderLineReader) setMaxLineLength(max int) {
+	r.maxLength = max
+}
+
+func (r *readerLineReader) setMaxLineLength(max int) {
+	r.maxLength = max
+}
+
+func (r *bufioLineReader) setMaxLineLength(max int) {
+	r.maxLength = max
+}
+
+func (r *sliceLineReader) peek(n int) []byte {
+	if n > len(r.remaining) {
+		n = len(r.remaining)
+	}
+	return r.remaining[:n]
+}
+
+func (r *bufioLineReader) peek(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	b, _ := r.reader.Peek(n)
+	return b
+}
+
+func (r *sliceLineReader) lookAhead() LineReader {
+	return &sliceLineReader{remaining: r.remaining}
+}
+
 func (r *sliceLineReader) ReadLine() ([]byte, error) {
 	i := bytes.IndexByte(r.remaining, '\n')
 	if r.remaining == nil {
@@ -73,6 +112,10 @@ func (r *byteReaderLineReader) ReadLine() ([]byte, error) {
 	if err == nil {
 		l = make([]byte, 0, lineLength)
 		for err == nil && b != '\n' {
+			if r.maxLength > 0 && len(l) == r.maxLength {
+				err = &LimitError{Limit: "MaxLineLength", Max: int64(r.maxLength)}
+				break
+			}
 			l = append(l, b)
 			b, err = r.reader.ReadByte()
 		}
@@ -107,6 +150,10 @@ func (r *readerLineReader) ReadLine() ([]byte, error) {
 	if n > 0 {
 		l = make([]byte, 0, lineLength)
 		for n > 0 && r.scratch[0] != '\n' {
+			if r.maxLength > 0 && len(l) == r.maxLength {
+				err = &LimitError{Limit: "MaxLineLength", Max: int64(r.maxLength)}
+				break
+			}
 			l = append(l, r.scratch[0])
 			n, err = r.reader.Read(r.scratch)
 		}
@@ -143,6 +190,10 @@ func (r *bufioLineReader) ReadLine() ([]byte, error) {
 
 		line = append(make([]byte, 0, lineLength), line...)
 		for err == nil && isPrefix {
+			if r.maxLength > 0 && len(line) > r.maxLength {
+				err = &LimitError{Limit: "MaxLineLength", Max: int64(r.maxLength)}
+				break
+			}
 			var linePart []byte
 			linePart, isPrefix, err = r.reader.ReadLine()
 			line = append(line, linePart...)
@@ -0,0 +1,223 @@
+// Package mailbox finds UU encoded attachments in the plain-text bodies of mail messages stored in mbox files and
+// Maildir directories.
+package mailbox
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"github.com/gsson/uu"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Attachment is a UU encoded entry found in a message
+type Attachment struct {
+	// MessageID is the Message-Id header of the message containing the entry
+	MessageID string
+	// Info describes the entry
+	Info *uu.FileInfo
+	// Reader decodes the entry. It is only valid until the next call to Scanner.Next.
+	Reader io.Reader
+}
+
+// Scanner walks the messages of a mailbox and finds the UU encoded entries in their bodies.
+//
+// Quoted-printable and base64 transfer encodings are undone, and the parts of multipart messages and attached
+// messages are searched separately. Entries quoted in replies and forwarded messages are found as well. Messages
+// that cannot be parsed are skipped.
+type Scanner struct {
+	next       func() ([]byte, error)
+	options    uu.ReaderOptions
+	attachment Attachment
+	messageID  string
+	bodies     [][]byte
+	lines      uu.LineReader
+	err        error
+}
+
+// NewMboxScanner creates a Scanner for the messages of an mbox file. Lines quoted as ">From " (in the mboxrd
+// convention) are unquoted.
+func NewMboxScanner(r io.Reader, options uu.ReaderOptions) *Scanner {
+	return &Scanner{next: mboxMessages(bufio.NewReader(r)), options: options}
+}
+
+// NewMaildirScanner creates a Scanner for the messages in the new and cur subdirectories of a Maildir directory
+func NewMaildirScanner(dir string, options uu.ReaderOptions) *Scanner {
+	return &Scanner{next: maildirMessages(dir), options: options}
+}
+
+// Next advances to the next entry, which is then returned by Attachment. It returns false when there are no more
+// entries, or if reading the mailbox fails, in which case Err returns the error.
+//
+// The rest of the current entry is skipped, and a damaged entry does not stop the scan.
+func (s *Scanner) Next() bool {
+	if s.attachment.Reader != nil {
+		io.Copy(ioutil.Discard, s.attachment.Reader)
+		s.attachment = Attachment{}
+	}
+
+	for s.err == nil {
+		if s.lines == nil {
+			if len(s.bodies) == 0 {
+				s.readMessage()
+				continue
+			}
+			s.lines, s.bodies = uu.NewQuoteDetectingLineReader(uu.NewSliceLineReader(s.bodies[0])), s.bodies[1:]
+		}
+
+		reader, err := uu.FindEntry(s.lines, s.options)
+		if err != nil {
+			s.lines = nil
+			continue
+		}
+		info, err := reader.FileInfo()
+		if err != nil {
+			continue
+		}
+		s.attachment = Attachment{MessageID: s.messageID, Info: info, Reader: reader}
+		return true
+	}
+	return false
+}
+
+// Attachment returns the entry found by the last call to Next
+func (s *Scanner) Attachment() Attachment {
+	return s.attachment
+}
+
+// Err returns the error that stopped the scan, or nil if the end of the mailbox was reached
+func (s *Scanner) Err() error {
+	if s.err == io.EOF {
+		return nil
+	}
+	return s.err
+}
+
+func (s *Scanner) readMessage() {
+	data, err := s.next()
+	if err != nil {
+		s.err = err
+		return
+	}
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+	s.messageID = strings.TrimSpace(msg.Header.Get("Message-Id"))
+	s.bodies = partBodies(textproto.MIMEHeader(msg.Header), msg.Body, nil)
+}
+
+// partBodies appends the decoded bodies of a part, or of the parts within it, to bodies
+func partBodies(header textproto.MIMEHeader, body io.Reader, bodies [][]byte) [][]byte {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err != nil {
+				return bodies
+			}
+			bodies = partBodies(part.Header, part, bodies)
+		}
+	}
+
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	data, _ := ioutil.ReadAll(body)
+
+	if mediaType == "message/rfc822" {
+		if msg, err := mail.ReadMessage(bytes.NewReader(data)); err == nil {
+			return partBodies(textproto.MIMEHeader(msg.Header), msg.Body, bodies)
+		}
+	}
+	return append(bodies, data)
+}
+
+func mboxMessages(r *bufio.Reader) func() ([]byte, error) {
+	var pending []byte
+	return func() ([]byte, error) {
+		var message []byte
+		for {
+			line := pending
+			pending = nil
+			if line == nil {
+				var err error
+				line, err = r.ReadBytes('\n')
+				if err == io.EOF && len(line) > 0 {
+					err = nil
+				}
+				if err == io.EOF && message != nil {
+					return message, nil
+				}
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			if bytes.HasPrefix(line, []byte("From ")) {
+				if message != nil {
+					pending = line
+					return message, nil
+				}
+				message = []byte{}
+				continue
+			}
+			if message == nil {
+				// Text before the first From line is not part of a message
+				continue
+			}
+			if unquoted := bytes.TrimLeft(line, ">"); len(unquoted) < len(line) && bytes.HasPrefix(unquoted, []byte("From ")) {
+				line = line[1:]
+			}
+			message = append(message, line...)
+		}
+	}
+}
+
+func maildirMessages(dir string) func() ([]byte, error) {
+	var files []string
+	listed := false
+	return func() ([]byte, error) {
+		if !listed {
+			listed = true
+			if _, err := os.Stat(dir); err != nil {
+				return nil, err
+			}
+			for _, sub := range []string{"new", "cur"} {
+				names, err := filepath.Glob(filepath.Join(dir, sub, "*"))
+				if err != nil {
+					return nil, err
+				}
+				sort.Strings(names)
+				files = append(files, names...)
+			}
+		}
+		for len(files) > 0 {
+			name := files[0]
+			files = files[1:]
+			if fi, err := os.Stat(name); err != nil || !fi.Mode().IsRegular() {
+				continue
+			}
+			return ioutil.ReadFile(name)
+		}
+		return nil, io.EOF
+	}
+}
@@ -0,0 +1,133 @@
+package mailbox
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/gsson/uu"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const catEntry = "begin 644 cat.txt\n#0V%T\n`\nend\n"
+
+const plainMessage = "From: someone@example.com\n" +
+	"Message-Id: <plain@example.com>\n" +
+	"\n" +
+	"Here you go:\n" +
+	catEntry +
+	">From the archive\n"
+
+const multipartMessage = "From: someone@example.com\n" +
+	"Message-Id: <multipart@example.com>\n" +
+	"Content-Type: multipart/mixed; boundary=XYZ\n" +
+	"\n" +
+	"--XYZ\n" +
+	"Content-Type: text/plain\n" +
+	"Content-Transfer-Encoding: quoted-printable\n" +
+	"\n" +
+	"begin 644 hello=2Etxt\n" +
+	",2&5L;&\\@5V]R;&0*\n" +
+	"`\n" +
+	"end\n" +
+	"--XYZ\n" +
+	"Content-Type: application/octet-stream\n" +
+	"Content-Transfer-Encoding: base64\n" +
+	"\n" +
+	"YmVnaW4gNjAwIGNhdDIudHh0CiMwViVUCmAKZW5kCg==\n" +
+	"--XYZ--\n"
+
+type found struct {
+	messageID string
+	name      string
+	contents  string
+}
+
+func scanAll(t *testing.T, s *Scanner) []found {
+	var result []found
+	for s.Next() {
+		a := s.Attachment()
+		contents, err := ioutil.ReadAll(a.Reader)
+		assert.Nil(t, err)
+		result = append(result, found{a.MessageID, a.Info.Name, string(contents)})
+	}
+	assert.Nil(t, s.Err())
+	return result
+}
+
+func TestMboxScanner(t *testing.T) {
+	mbox := "From someone@example.com Thu Jan  1 00:00:00 1970\n" +
+		plainMessage +
+		"\n" +
+		"From someone@example.com Thu Jan  1 00:00:00 1970\n" +
+		multipartMessage
+
+	s := NewMboxScanner(strings.NewReader(mbox), uu.ReaderOptions{})
+	assert.Equal(t, []found{
+		{"<plain@example.com>", "cat.txt", "Cat"},
+		{"<multipart@example.com>", "hello.txt", "Hello World\n"},
+		{"<multipart@example.com>", "cat2.txt", "Cat"},
+	}, scanAll(t, s))
+}
+
+func TestMboxMessages_unquotesFrom(t *testing.T) {
+	next := mboxMessages(bufioReader("From a\nx\n>From b\n>>From c\nFrom d\ny"))
+	message, err := next()
+	assert.Nil(t, err)
+	assert.Equal(t, "x\nFrom b\n>From c\n", string(message))
+	message, err = next()
+	assert.Nil(t, err)
+	assert.Equal(t, "y", string(message))
+	_, err = next()
+	assert.NotNil(t, err)
+}
+
+func TestMaildirScanner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "maildir")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, sub := range []string{"new", "cur", "tmp"} {
+		os.Mkdir(filepath.Join(dir, sub), 0755)
+	}
+	ioutil.WriteFile(filepath.Join(dir, "new", "1.host"), []byte(plainMessage), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "cur", "2.host:2,S"), []byte(multipartMessage), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "tmp", "3.host"), []byte(plainMessage), 0644)
+
+	s := NewMaildirScanner(dir, uu.ReaderOptions{})
+	assert.Equal(t, []found{
+		{"<plain@example.com>", "cat.txt", "Cat"},
+		{"<multipart@example.com>", "hello.txt", "Hello World\n"},
+		{"<multipart@example.com>", "cat2.txt", "Cat"},
+	}, scanAll(t, s))
+}
+
+func TestMaildirScanner_missingDirectory(t *testing.T) {
+	s := NewMaildirScanner(filepath.Join(os.TempDir(), "no-such-maildir"), uu.ReaderOptions{})
+	assert.False(t, s.Next())
+	assert.NotNil(t, s.Err())
+}
+
+func TestScanner_skipsUnreadEntries(t *testing.T) {
+	message := "Message-Id: <two@example.com>\n\n" + catEntry + strings.Replace(catEntry, "cat.txt", "dog.txt", 1)
+	s := NewMboxScanner(strings.NewReader("From x\n"+message), uu.ReaderOptions{})
+	var names []string
+	for s.Next() {
+		names = append(names, s.Attachment().Info.Name)
+	}
+	assert.Equal(t, []string{"cat.txt", "dog.txt"}, names)
+}
+
+func TestScanner_quotedEntries(t *testing.T) {
+	message := "Message-Id: <quoted@example.com>\n\nYou wrote:\n> begin 644 cat.txt\n> #0V%T\n> `\n> end\n"
+	s := NewMboxScanner(strings.NewReader("From x\n"+message), uu.ReaderOptions{})
+	assert.Equal(t, []found{{"<quoted@example.com>", "cat.txt", "Cat"}}, scanAll(t, s))
+}
+
+func bufioReader(s string) *bufio.Reader {
+	return bufio.NewReader(bytes.NewReader([]byte(s)))
+}
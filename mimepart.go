@@ -0,0 +1,61 @@
+package uu
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+)
+
+// MIMEPart is the decoded content of a MIME part or mail message with a uuencode transfer encoding
+type MIMEPart struct {
+	Reader
+	// FileName is the file name given by the Content-Disposition header, or the one in the header of the encoded
+	// data if there is none
+	FileName string
+}
+
+// IsUUEncodedPart reports whether the Content-Transfer-Encoding of a MIME header is one of x-uuencode, uuencode,
+// x-uue or uue
+func IsUUEncodedPart(header textproto.MIMEHeader) bool {
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))) {
+	case "x-uuencode", "uuencode", "x-uue", "uue":
+		return true
+	}
+	return false
+}
+
+// NewMIMEPartReader decodes the uuencoded body of a MIME part. The header of the encoded data is read before
+// returning, and leading blank lines are skipped.
+func NewMIMEPartReader(header textproto.MIMEHeader, body io.Reader) (*MIMEPart, error) {
+	if !IsUUEncodedPart(header) {
+		return nil, newError("Not a uuencoded part: " + header.Get("Content-Transfer-Encoding"))
+	}
+
+	r, err := readEntry(NewBufioLineReader(bufio.NewReader(body)), ReaderOptions{})
+	if err == io.EOF {
+		return nil, ErrTruncated
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fileName := r.info.Name
+	if _, params, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		fileName = params["filename"]
+	}
+	return &MIMEPart{Reader: r, FileName: fileName}, nil
+}
+
+// NewPartReader decodes a uuencoded part of a multipart message
+func NewPartReader(part *multipart.Part) (*MIMEPart, error) {
+	return NewMIMEPartReader(part.Header, part)
+}
+
+// NewMessageReader decodes the body of a mail message with a uuencode transfer encoding
+func NewMessageReader(msg *mail.Message) (*MIMEPart, error) {
+	return NewMIMEPartReader(textproto.MIMEHeader(msg.Header), msg.Body)
+}
@@ -0,0 +1,78 @@
+package uu
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+const catEntry = "begin 644 cat.txt\n#0V%T\n`\nend\n"
+
+func TestIsUUEncodedPart(t *testing.T) {
+	for _, encoding := range []string{"x-uuencode", "uuencode", "X-UUE", "uue "} {
+		assert.True(t, IsUUEncodedPart(textproto.MIMEHeader{"Content-Transfer-Encoding": {encoding}}), encoding)
+	}
+	assert.False(t, IsUUEncodedPart(textproto.MIMEHeader{"Content-Transfer-Encoding": {"base64"}}))
+	assert.False(t, IsUUEncodedPart(textproto.MIMEHeader{}))
+}
+
+func TestNewPartReader(t *testing.T) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	pw, _ := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain"}})
+	pw.Write([]byte("Hello"))
+	pw, _ = mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"application/octet-stream"},
+		"Content-Transfer-Encoding": {"x-uuencode"},
+		"Content-Disposition":       {"attachment; filename=\"kitten.txt\""},
+	})
+	pw.Write([]byte("\r\n" + catEntry))
+	mw.Close()
+
+	mr := multipart.NewReader(&buf, mw.Boundary())
+	part, err := mr.NextPart()
+	assert.Nil(t, err)
+	_, err = NewPartReader(part)
+	assert.EqualError(t, err, "Not a uuencoded part: ")
+
+	part, err = mr.NextPart()
+	assert.Nil(t, err)
+	decoded, err := NewPartReader(part)
+	assert.Nil(t, err)
+	assert.Equal(t, "kitten.txt", decoded.FileName)
+
+	contents, err := ioutil.ReadAll(decoded)
+	assert.Nil(t, err)
+	assert.Equal(t, "Cat", string(contents))
+
+	fileInfo, _ := decoded.FileInfo()
+	assert.Equal(t, "cat.txt", fileInfo.Name)
+}
+
+func TestNewMessageReader(t *testing.T) {
+	message := "From: someone@example.com\r\n" +
+		"Subject: cat\r\n" +
+		"Content-Transfer-Encoding: uuencode\r\n" +
+		"\r\n" +
+		catEntry
+	msg, err := mail.ReadMessage(strings.NewReader(message))
+	assert.Nil(t, err)
+
+	decoded, err := NewMessageReader(msg)
+	assert.Nil(t, err)
+	assert.Equal(t, "cat.txt", decoded.FileName)
+
+	contents, err := ioutil.ReadAll(decoded)
+	assert.Nil(t, err)
+	assert.Equal(t, "Cat", string(contents))
+}
+
+func TestNewMIMEPartReader_emptyBody(t *testing.T) {
+	_, err := NewMIMEPartReader(textproto.MIMEHeader{"Content-Transfer-Encoding": {"x-uue"}}, strings.NewReader("\n\n"))
+	assert.Equal(t, ErrTruncated, err)
+}
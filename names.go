@@ -0,0 +1,148 @@
+package uu
+
+import (
+	"bytes"
+	"encoding/base64"
+	"mime"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// parseName decodes the file name of a header from the raw bytes following the mode.
+//
+// Trailing whitespace, including any carriage return, is removed. A name enclosed in double quotes is
+// unquoted, where \" and \\ are the only escape sequences. RFC 2047 encoded-words are then decoded, and finally
+// any invalid UTF-8 is replaced by U+FFFD. The raw bytes are returned as well if the name was changed by any of
+// these steps, and nil otherwise.
+func parseName(raw []byte) (string, []byte) {
+	trimmed := bytes.TrimRight(raw, " \t\r\n")
+
+	name := string(trimmed)
+	if unquoted, ok := unquoteName(trimmed); ok {
+		name = unquoted
+	}
+	if strings.Contains(name, "=?") {
+		if decoded, err := new(mime.WordDecoder).DecodeHeader(name); err == nil {
+			name = decoded
+		}
+	}
+	if !utf8.ValidString(name) {
+		name = toValidUTF8(name)
+	}
+
+	if name == string(raw) {
+		return name, nil
+	}
+	return name, append([]byte(nil), raw...)
+}
+
+// parseEncodedName decodes a Base64 encoded name from a begin-encoded header. As with parseName, invalid UTF-8
+// is replaced by U+FFFD, and the decoded bytes are returned as well if they differ from the name.
+func parseEncodedName(raw []byte) (string, []byte, error) {
+	trimmed := bytes.TrimRight(raw, " \t\r\n")
+	decoded, err := base64.StdEncoding.DecodeString(string(trimmed))
+	if err != nil {
+		decoded, err = base64.RawStdEncoding.DecodeString(string(trimmed))
+	}
+	if err != nil {
+		return "", nil, newError("Invalid encoded file name: " + err.Error())
+	}
+
+	if !utf8.Valid(decoded) {
+		return toValidUTF8(string(decoded)), decoded, nil
+	}
+	return string(decoded), nil, nil
+}
+
+// toValidUTF8 replaces each run of invalid UTF-8 in s with U+FFFD, as strings.ToValidUTF8 does from Go 1.13
+func toValidUTF8(s string) string {
+	var b []byte
+	invalid := false
+	for i, r := range s {
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(s[i:]); size == 1 {
+				if !invalid {
+					b = append(b, "\uFFFD"...)
+				}
+				invalid = true
+				continue
+			}
+		}
+		invalid = false
+		b = append(b, s[i:i+utf8.RuneLen(r)]...)
+	}
+	return string(b)
+}
+
+func unquoteName(in []byte) (string, bool) {
+	if len(in) < 2 || in[0] != '"' {
+		return "", false
+	}
+
+	out := make([]byte, 0, len(in)-2)
+	for i := 1; i < len(in); i++ {
+		switch c := in[i]; {
+		case c == '\\' && i+1 < len(in) && (in[i+1] == '"' || in[i+1] == '\\'):
+			out = append(out, in[i+1])
+			i++
+		case c == '"':
+			if i != len(in)-1 {
+				return "", false
+			}
+			return string(out), true
+		default:
+			out = append(out, c)
+		}
+	}
+	return "", false
+}
+
+// formatName returns the name as it should appear in a header, so that parseName returns it unchanged.
+//
+// Names with control characters are written as an RFC 2047 encoded-word, while names that would otherwise be
+// trimmed or unquoted are enclosed in double quotes. A name that is itself a valid encoded-word cannot be
+// represented, and is decoded when read back.
+func formatName(name string) string {
+	if strings.IndexFunc(name, isControl) >= 0 {
+		return mime.BEncoding.Encode("utf-8", name)
+	}
+	if name == "" || strings.TrimRight(name, " \t") != name || name[0] == '"' {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(name) + `"`
+	}
+	return name
+}
+
+// entryPath turns the name of an entry into a relative, slash-separated path, removing leading slashes and "./"
+// elements. It reports false if the name is empty or leads outside the current directory.
+func entryPath(name string) (string, bool) {
+	name = strings.TrimLeft(name, "/")
+	for strings.HasPrefix(name, "./") {
+		name = strings.TrimLeft(name[2:], "/")
+	}
+	name = path.Clean(name)
+	return name, name != "." && validPath(name)
+}
+
+// validPath reports whether a cleaned path has no ".." elements, as fs.ValidPath from Go 1.16 does
+func validPath(name string) bool {
+	for _, elem := range strings.Split(name, "/") {
+		if elem == "" || elem == "." || elem == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+func isControl(r rune) bool {
+	return r < ' ' || r == 0x7f
+}
+
+// RawName returns the file name exactly as it appeared in the header, before any unquoting, trimming or
+// decoding. For a begin-encoded header, it returns the Base64 decoded bytes, which may not be valid UTF-8.
+func (fi *FileInfo) RawName() []byte {
+	if fi.rawName != nil {
+		return fi.rawName
+	}
+	return []byte(fi.Name)
+}
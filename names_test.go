@@ -0,0 +1,144 @@
+package uu
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"testing"
+)
+
+func TestParseName(t *testing.T) {
+	assertNameParsed(t, "hello.txt", "hello.txt", false)
+	assertNameParsed(t, "My File.doc", "My File.doc", false)
+	assertNameParsed(t, " leading.txt", " leading.txt", false)
+	assertNameParsed(t, "hello.txt\r", "hello.txt", true)
+	assertNameParsed(t, "hello.txt \t ", "hello.txt", true)
+	assertNameParsed(t, "\"My File.doc\"", "My File.doc", true)
+	assertNameParsed(t, "\"My File.doc\"\r", "My File.doc", true)
+	assertNameParsed(t, "\"say \\\"hi\\\".txt\"", "say \"hi\".txt", true)
+	assertNameParsed(t, "\"C:\\temp\\\\x.txt\"", "C:\\temp\\x.txt", true)
+	assertNameParsed(t, "\"unterminated", "\"unterminated", false)
+	assertNameParsed(t, "\"a\" \"b\"", "\"a\" \"b\"", false)
+	assertNameParsed(t, "\"", "\"", false)
+	assertNameParsed(t, "=?utf-8?q?r=C3=A4ksm=C3=B6rg=C3=A5s.txt?=", "räksmörgås.txt", true)
+	assertNameParsed(t, "\"=?ISO-8859-1?B?5OT k?=\"", "=?ISO-8859-1?B?5OT k?=", true)
+	assertNameParsed(t, "=?x-unknown?q?abc?=", "=?x-unknown?q?abc?=", false)
+	assertNameParsed(t, "r\xe4ksm\xf6rg\xe5s.txt", "r\uFFFDksm\uFFFDrg\uFFFDs.txt", true)
+}
+
+func TestFormatName(t *testing.T) {
+	assertNameFormatted(t, "hello.txt", "hello.txt")
+	assertNameFormatted(t, "My File.doc", "My File.doc")
+	assertNameFormatted(t, "räksmörgås.txt", "räksmörgås.txt")
+	assertNameFormatted(t, "trailing ", "\"trailing \"")
+	assertNameFormatted(t, "\"quoted\"", "\"\\\"quoted\\\"\"")
+	assertNameFormatted(t, "", "\"\"")
+	assertNameFormatted(t, "line\nbreak", "=?utf-8?b?bGluZQpicmVhaw==?=")
+}
+
+func TestFileInfo_RawName(t *testing.T) {
+	info, err := parseBegin([]byte("begin 644 \"My File.doc\"\r"))
+	assert.Nil(t, err)
+	assert.Equal(t, "My File.doc", info.Name)
+	assert.Equal(t, []byte("\"My File.doc\"\r"), info.RawName())
+
+	info, err = parseBegin([]byte("begin 644 hello.txt"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("hello.txt"), info.RawName())
+}
+
+func TestUuWriter_roundTripNames(t *testing.T) {
+	names := []string{"hello.txt", "My File.doc", "trailing ", "\"quoted\"", "tab\tname", "line\nbreak\r", "räksmörgås.txt", ""}
+	for _, name := range names {
+		var out bytes.Buffer
+		w := NewWriter(&out, FileInfo{Name: name, Mode: 0644})
+		assert.Nil(t, w.Close())
+
+		r := NewReader(NewSliceLineReader(out.Bytes()))
+		info, err := r.FileInfo()
+		assert.Nil(t, err)
+		assert.Equal(t, name, info.Name)
+
+		_, err = ioutil.ReadAll(r)
+		assert.Nil(t, err)
+	}
+}
+
+func assertNameParsed(t *testing.T, raw string, expected string, changed bool) {
+	name, rawName := parseName([]byte(raw))
+	assert.Equal(t, expected, name)
+	if changed {
+		assert.Equal(t, []byte(raw), rawName)
+	} else {
+		assert.Nil(t, rawName)
+	}
+}
+
+func assertNameFormatted(t *testing.T, name string, expected string) {
+	formatted := formatName(name)
+	assert.Equal(t, expected, formatted)
+
+	parsed, _ := parseName([]byte(formatted))
+	assert.Equal(t, name, parsed)
+}
+
+func TestParseBeginLine_encodedName(t *testing.T) {
+	assertBeginLineParsed(t, "begin-encoded 644 aGVsbG8udHh0", FileInfo{Encoding: UUEncoding, Mode: 0644, Name: "hello.txt", EncodedName: true})
+	assertBeginLineParsed(t, "begin-base64-encoded 600 TXkgRmlsZS5kb2M=\r", FileInfo{Encoding: Base64Encoding, Mode: 0600, Name: "My File.doc", EncodedName: true})
+	assertBeginLineParsed(t, "begin-encoded 644 TXkgRmlsZS5kb2M", FileInfo{Encoding: UUEncoding, Mode: 0644, Name: "My File.doc", EncodedName: true})
+
+	info, err := parseBegin([]byte("begin-encoded 644 cuRrc232cmflcy50eHQ="))
+	assert.Nil(t, err)
+	assert.Equal(t, "r�ksm�rg�s.txt", info.Name)
+	assert.Equal(t, []byte("r\xe4ksm\xf6rg\xe5s.txt"), info.RawName())
+
+	assertBeginLineFails(t, "begin-encoded 644 hello.txt", "Invalid encoded file name: illegal base64 data at input byte 5")
+	assertBeginLineFails(t, "begin-encoded-base64 644 aGVsbG8udHh0", "Invalid header")
+}
+
+func TestFormatBegin_encodedName(t *testing.T) {
+	assert.Equal(t, "begin-encoded 644 aGVsbG8udHh0\n", string(formatBegin(&FileInfo{Name: "hello.txt", Mode: 0644, EncodedName: true}, nil)))
+	assert.Equal(t, "begin-base64-encoded 644 bGluZQpicmVhaw==\n", string(formatBegin(&FileInfo{Encoding: Base64Encoding, Name: "line\nbreak", Mode: 0644, EncodedName: true}, nil)))
+}
+
+func TestUuWriter_roundTripEncodedNames(t *testing.T) {
+	names := []string{"hello.txt", "line\nbreak\r", "r\xe4ksm\xf6rg\xe5s.txt", "=?utf-8?q?abc?=", ""}
+	for _, encoding := range []Encoding{UUEncoding, Base64Encoding} {
+		for _, name := range names {
+			var out bytes.Buffer
+			w := NewWriter(&out, FileInfo{Encoding: encoding, Name: name, Mode: 0644, EncodedName: true})
+			_, err := w.Write([]byte("Cat"))
+			assert.Nil(t, err)
+			assert.Nil(t, w.Close())
+
+			r := NewReader(NewSliceLineReader(out.Bytes()))
+			info, err := r.FileInfo()
+			assert.Nil(t, err)
+			assert.Equal(t, []byte(name), info.RawName())
+			assert.True(t, info.EncodedName)
+
+			contents, err := ioutil.ReadAll(r)
+			assert.Nil(t, err)
+			assert.Equal(t, "Cat", string(contents))
+		}
+	}
+}
+
+func TestToValidUTF8(t *testing.T) {
+	assert.Equal(t, "abc", toValidUTF8("abc"))
+	assert.Equal(t, "a�b", toValidUTF8("a\xff\xfeb"))
+	assert.Equal(t, "�r��", toValidUTF8("\xe4r�\xf6"))
+	assert.Equal(t, "ä�", toValidUTF8("ä\xc3"))
+}
+
+func TestEntryPath(t *testing.T) {
+	for name, expected := range map[string]string{"a": "a", "/a/b": "a/b", "./a//b/": "a/b", "a/../b": "b", ".//a": "a"} {
+		p, ok := entryPath(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, expected, p)
+	}
+	for _, name := range []string{"", "/", ".", "..", "../a", "a/../../b"} {
+		_, ok := entryPath(name)
+		assert.False(t, ok, name)
+	}
+}
@@ -0,0 +1,180 @@
+// Package nntp is a minimal NNTP client for fetching articles, with the bodies read as uu.LineReaders so UU
+// encoded binaries can be decoded as they arrive.
+package nntp
+
+import (
+	"errors"
+	"github.com/gsson/uu"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// Client is a connection to an NNTP server
+type Client struct {
+	text *textproto.Conn
+	body *bodyLineReader
+}
+
+// Dial connects to the NNTP server at addr and reads its greeting
+func Dial(network, addr string) (*Client, error) {
+	conn, err := net.Dial(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewClient creates a Client using an existing connection, and reads the greeting of the server
+func NewClient(conn io.ReadWriteCloser) (*Client, error) {
+	c := &Client{text: textproto.NewConn(conn)}
+	if _, _, err := c.text.ReadCodeLine(20); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Group selects a newsgroup, so articles can be fetched by number. It returns the estimated number of articles
+// and the lowest and highest article numbers.
+func (c *Client) Group(name string) (count, low, high int, err error) {
+	if err = checkArgument(name); err != nil {
+		return 0, 0, 0, err
+	}
+	_, line, err := c.cmd(211, "GROUP %s", name)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	fields := strings.Fields(line)
+	numbers := make([]int, 3)
+	for i := range numbers {
+		if i < len(fields) {
+			numbers[i], err = strconv.Atoi(fields[i])
+		}
+		if i >= len(fields) || err != nil {
+			return 0, 0, 0, textproto.ProtocolError("invalid GROUP response: " + line)
+		}
+	}
+	return numbers[0], numbers[1], numbers[2], nil
+}
+
+// Article fetches an article by message id (including the angle brackets) or by number in the current group. It
+// returns the header, and the dot-unstuffed body as a uu.LineReader that must be read to io.EOF, or abandoned by
+// issuing the next command, before the Client is used again. An id holding whitespace or control characters is
+// rejected without being sent.
+func (c *Client) Article(id string) (textproto.MIMEHeader, uu.LineReader, error) {
+	if err := checkArgument(id); err != nil {
+		return nil, nil, err
+	}
+	if _, _, err := c.cmd(220, "ARTICLE %s", id); err != nil {
+		return nil, nil, err
+	}
+	header, err := c.text.ReadMIMEHeader()
+	if err != nil {
+		return nil, nil, err
+	}
+	return header, c.newBody(), nil
+}
+
+// Body fetches the body of an article by message id or by number, like Article
+func (c *Client) Body(id string) (uu.LineReader, error) {
+	if err := checkArgument(id); err != nil {
+		return nil, err
+	}
+	if _, _, err := c.cmd(222, "BODY %s", id); err != nil {
+		return nil, err
+	}
+	return c.newBody(), nil
+}
+
+// Quit ends the session and closes the connection
+func (c *Client) Quit() error {
+	_, _, err := c.cmd(205, "QUIT")
+	if cerr := c.text.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Close closes the connection without ending the session
+func (c *Client) Close() error {
+	return c.text.Close()
+}
+
+// checkArgument rejects a command argument that is empty or holds whitespace or control characters, which could
+// end the command early and inject another one
+func checkArgument(arg string) error {
+	if arg == "" {
+		return errors.New("nntp: empty argument")
+	}
+	for i := 0; i < len(arg); i++ {
+		if arg[i] <= ' ' || arg[i] == 0x7f {
+			return errors.New("nntp: invalid argument: " + strconv.Quote(arg))
+		}
+	}
+	return nil
+}
+
+func (c *Client) cmd(expectCode int, format string, args ...interface{}) (int, string, error) {
+	if c.body != nil {
+		if err := c.body.drain(); err != nil {
+			return 0, "", err
+		}
+		c.body = nil
+	}
+	if err := c.text.PrintfLine(format, args...); err != nil {
+		return 0, "", err
+	}
+	return c.text.ReadCodeLine(expectCode)
+}
+
+func (c *Client) newBody() uu.LineReader {
+	c.body = &bodyLineReader{reader: &c.text.Reader}
+	return c.body
+}
+
+// bodyLineReader reads the lines of a multi-line response up to the terminating ".", removing the dot that
+// escapes lines starting with "."
+type bodyLineReader struct {
+	reader *textproto.Reader
+	err    error
+}
+
+func (r *bodyLineReader) ReadLine() ([]byte, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	line, err := r.reader.ReadLineBytes()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		r.err = err
+		return nil, err
+	}
+	if len(line) > 0 && line[0] == '.' {
+		if len(line) == 1 {
+			r.err = io.EOF
+			return nil, io.EOF
+		}
+		line = line[1:]
+	}
+	return line, nil
+}
+
+func (r *bodyLineReader) drain() error {
+	for {
+		if _, err := r.ReadLine(); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package nntp
+
+import (
+	"github.com/gsson/uu"
+	"github.com/gsson/uu/nntp/nntptest"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"net/textproto"
+	"testing"
+)
+
+// The second line of the entry starts with a dot, and is dot-stuffed in transit
+const dottedEntry = "begin 644 dots.txt\n" +
+	".2&5L;&\\L(%=O<FQD(2$`\n" +
+	"`\n" +
+	"end\n"
+
+func newTestServer() *nntptest.Server {
+	return nntptest.NewServer(
+		nntptest.Article{
+			MessageID: "<dots@example.com>",
+			Group:     "alt.binaries.test",
+			Header:    "Subject: dots\nMessage-ID: <dots@example.com>\n",
+			Body:      dottedEntry,
+		},
+		nntptest.Article{
+			MessageID: "<begin@example.com>",
+			Group:     "alt.binaries.test",
+			Header:    "Subject: begin\n",
+			Body:      ".begin 644 cat.txt\n#0V%T\n`\nend\n",
+		},
+	)
+}
+
+func TestClient_Body(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	c, err := Dial("tcp", server.Addr)
+	assert.Nil(t, err)
+	defer c.Quit()
+
+	body, err := c.Body("<dots@example.com>")
+	assert.Nil(t, err)
+	contents, err := ioutil.ReadAll(uu.NewReader(body))
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello, World!!", string(contents))
+}
+
+func TestClient_Article(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	c, err := Dial("tcp", server.Addr)
+	assert.Nil(t, err)
+	defer c.Quit()
+
+	count, low, high, err := c.Group("alt.binaries.test")
+	assert.Nil(t, err)
+	assert.Equal(t, []int{2, 1, 2}, []int{count, low, high})
+
+	header, body, err := c.Article("1")
+	assert.Nil(t, err)
+	assert.Equal(t, "dots", header.Get("Subject"))
+	reader := uu.NewReader(body)
+	fileInfo, err := reader.FileInfo()
+	assert.Nil(t, err)
+	assert.Equal(t, "dots.txt", fileInfo.Name)
+
+	// The rest of the body is skipped by the next command
+	body, err = c.Body("2")
+	assert.Nil(t, err)
+	line, err := body.ReadLine()
+	assert.Nil(t, err)
+	assert.Equal(t, ".begin 644 cat.txt", string(line))
+}
+
+func TestClient_bodyEnds(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	c, err := Dial("tcp", server.Addr)
+	assert.Nil(t, err)
+	defer c.Quit()
+
+	body, err := c.Body("<dots@example.com>")
+	assert.Nil(t, err)
+	var lines []string
+	for {
+		line, err := body.ReadLine()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err)
+		lines = append(lines, string(line))
+	}
+	assert.Equal(t, []string{"begin 644 dots.txt", ".2&5L;&\\L(%=O<FQD(2$`", "`", "end"}, lines)
+}
+
+func TestClient_errors(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	c, err := Dial("tcp", server.Addr)
+	assert.Nil(t, err)
+	defer c.Quit()
+
+	_, err = c.Body("<missing@example.com>")
+	assert.Equal(t, 430, err.(*textproto.Error).Code)
+
+	_, _, _, err = c.Group("alt.empty")
+	assert.Equal(t, 411, err.(*textproto.Error).Code)
+}
+
+func TestClient_invalidArguments(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	c, err := Dial("tcp", server.Addr)
+	assert.Nil(t, err)
+	defer c.Quit()
+
+	_, err = c.Body("<dots@example.com>\r\nQUIT")
+	assert.EqualError(t, err, `nntp: invalid argument: "<dots@example.com>\r\nQUIT"`)
+	_, _, err = c.Article("<a b@example.com>")
+	assert.EqualError(t, err, `nntp: invalid argument: "<a b@example.com>"`)
+	_, _, err = c.Article("")
+	assert.EqualError(t, err, "nntp: empty argument")
+	_, _, _, err = c.Group("alt.binaries.test\n")
+	assert.NotNil(t, err)
+
+	// The connection is still usable
+	_, err = c.Body("<dots@example.com>")
+	assert.Nil(t, err)
+}
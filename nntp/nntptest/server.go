@@ -0,0 +1,170 @@
+// Package nntptest provides an in-process NNTP server for testing NNTP clients
+package nntptest
+
+import (
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Article is an article served by a Server
+type Article struct {
+	// MessageID is the message id, including the angle brackets
+	MessageID string
+	// Group is the newsgroup the article is posted to
+	Group string
+	// Header holds the header lines, not including the blank line ending the header
+	Header string
+	// Body is the body of the article. It is dot-stuffed when sent.
+	Body string
+}
+
+// Server is an NNTP server listening on a local port, serving a fixed set of articles. It supports the GROUP,
+// ARTICLE, BODY, MODE READER and QUIT commands.
+type Server struct {
+	// Addr is the address the server listens on, in the form host:port
+	Addr string
+
+	listener net.Listener
+	articles []Article
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	conns    map[net.Conn]bool
+}
+
+// NewServer starts a Server serving articles. Within each group, the articles are numbered from 1 in the order
+// given.
+func NewServer(articles ...Article) *Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic("nntptest: failed to listen: " + err.Error())
+	}
+	s := &Server{Addr: listener.Addr().String(), listener: listener, articles: articles, conns: make(map[net.Conn]bool)}
+	s.wg.Add(1)
+	go s.serve()
+	return s
+}
+
+// Close stops the Server, closing any open connections
+func (s *Server) Close() {
+	s.listener.Close()
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = true
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(textproto.NewConn(conn))
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+func (s *Server) handle(c *textproto.Conn) {
+	defer c.Close()
+	if c.PrintfLine("200 nntptest ready") != nil {
+		return
+	}
+
+	group := ""
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			c.PrintfLine("500 Unknown command")
+			continue
+		}
+
+		switch cmd := strings.ToUpper(fields[0]); {
+		case cmd == "QUIT":
+			c.PrintfLine("205 Bye")
+			return
+		case cmd == "MODE" && len(fields) == 2 && strings.ToUpper(fields[1]) == "READER":
+			c.PrintfLine("200 Reader mode")
+		case cmd == "GROUP" && len(fields) == 2:
+			numbers := s.numbers(fields[1])
+			if len(numbers) == 0 {
+				c.PrintfLine("411 No such group")
+				continue
+			}
+			group = fields[1]
+			c.PrintfLine("211 %d 1 %d %s", len(numbers), len(numbers), group)
+		case (cmd == "ARTICLE" || cmd == "BODY") && len(fields) == 2:
+			s.sendArticle(c, cmd, group, fields[1])
+		default:
+			c.PrintfLine("500 Unknown command")
+		}
+	}
+}
+
+// numbers returns the articles of a group, in article number order
+func (s *Server) numbers(group string) []*Article {
+	var numbers []*Article
+	for i := range s.articles {
+		if s.articles[i].Group == group {
+			numbers = append(numbers, &s.articles[i])
+		}
+	}
+	return numbers
+}
+
+func (s *Server) find(group string, id string) (int, *Article) {
+	if strings.HasPrefix(id, "<") {
+		for i := range s.articles {
+			if s.articles[i].MessageID == id {
+				return 0, &s.articles[i]
+			}
+		}
+		return 0, nil
+	}
+	n, err := strconv.Atoi(id)
+	numbers := s.numbers(group)
+	if err != nil || n < 1 || n > len(numbers) {
+		return 0, nil
+	}
+	return n, numbers[n-1]
+}
+
+func (s *Server) sendArticle(c *textproto.Conn, cmd string, group string, id string) {
+	n, article := s.find(group, id)
+	if article == nil {
+		c.PrintfLine("430 No such article")
+		return
+	}
+
+	code := 222
+	if cmd == "ARTICLE" {
+		code = 220
+	}
+	c.PrintfLine("%d %d %s", code, n, article.MessageID)
+
+	w := c.DotWriter()
+	if cmd == "ARTICLE" {
+		w.Write([]byte(strings.TrimSuffix(article.Header, "\n") + "\n\n"))
+	}
+	w.Write([]byte(article.Body))
+	w.Close()
+}
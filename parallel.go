@@ -0,0 +1,212 @@
+package uu
+
+import (
+	"io"
+	"runtime"
+	"sync"
+)
+
+// minLinesPerWorker keeps tiny entries from being split into chunks that cost more to schedule than to decode
+const minLinesPerWorker = 1024
+
+type payloadLine struct {
+	line   []byte
+	number int
+	offset int
+	length int
+}
+
+// DecodeParallel decodes a single UU encoded entry held entirely in memory, using up to workers goroutines.
+//
+// The line boundaries are located up front and validated, the output is allocated in one piece using the
+// line length characters, and the payload lines are then decoded in chunks concurrently. If workers is less
+// than one, runtime.GOMAXPROCS(0) is used.
+//
+// The result matches reading the same data with NewReader(NewSliceLineReader(data)) to the end: on a decoding
+// error the returned slice contains everything decoded before the failing line, and the error is the one the
+// Reader would have returned.
+func DecodeParallel(data []byte, workers int) (*FileInfo, []byte, error) {
+	return DecodeParallelWithOptions(data, workers, ReaderOptions{})
+}
+
+// DecodeParallelWithOptions decodes an in-memory entry like DecodeParallel, configured by options. Only
+// VerifyLineChecksums and Alphabet are supported; setting any other field of the ReaderOptions returns an error.
+func DecodeParallelWithOptions(data []byte, workers int, options ReaderOptions) (*FileInfo, []byte, error) {
+	if options.AllowTruncated || options.Recovery != NoRecovery || options.VerifySize || len(options.Hashes) > 0 {
+		return nil, nil, errUnsupportedOptions
+	}
+	reader := NewSliceLineReader(data)
+
+	line, err := reader.ReadLine()
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := parseBegin(line)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var encodedSum bsdSum
+	encodedSum.Write(line)
+	encodedSum.Write([]byte{'\n'})
+	number := 1
+	if err = readTableLines(info, reader, &number, &encodedSum, options.Alphabet); err != nil {
+		return nil, nil, err
+	}
+	lines, number, size, err := scanPayloadLines(info, reader, number, &encodedSum)
+
+	out, derr := decodePayloadLines(info, lines, make([]byte, size), workers, options.VerifyLineChecksums)
+	info.decoded = int64(len(out))
+	if derr != nil || err != nil {
+		info.estimate, info.estimated = int64(size), true
+	}
+	if derr != nil {
+		return info, out, derr
+	}
+
+	info.complete = err == nil
+	if err == nil {
+		findSizeLine(info, reader.(lookAheadLineReader).lookAhead())
+		var decodedSum bsdSum
+		decodedSum.Write(out)
+		err = readSumLine(reader, number+1, &decodedSum, &encodedSum)
+	}
+	return info, out, err
+}
+
+// scanPayloadLines collects the payload lines up to the terminating line and their offsets in the decoded
+// output, stopping at the first line the sequential Reader would fail on. It returns the number of the last line
+// read, and an error that is nil if the entry is complete, including the trailer.
+func scanPayloadLines(info *FileInfo, reader LineReader, number int, encodedSum *bsdSum) ([]payloadLine, int, int, error) {
+	var lines []payloadLine
+	size := 0
+	for {
+		line, err := readCountedLine(reader, &number, encodedSum)
+		if err != nil {
+			return lines, number, size, truncated(info, err)
+		}
+		if line, err = standardLine(info, line, nil); err != nil {
+			return lines, number, size, err
+		}
+
+		outLength, err := payloadLength(info, line)
+		if err == io.EOF {
+			return lines, number, size, readTrailer(info, reader, &number, encodedSum)
+		}
+		if isShortLine(err) {
+			if _, rerr := reader.ReadLine(); rerr == io.EOF {
+				return lines, number, size, truncated(info, rerr)
+			}
+		}
+		if err != nil {
+			return lines, number, size, err
+		}
+
+		lines = append(lines, payloadLine{line: line, number: number, offset: size, length: outLength})
+		size += outLength
+	}
+}
+
+// readTableLines reads the alphabet of the entry if the header is followed by a table line. Otherwise, the
+// provided alphabet is used.
+func readTableLines(info *FileInfo, reader LineReader, number *int, encodedSum *bsdSum, alphabet string) error {
+	if info.Encoding != UUEncoding {
+		return nil
+	}
+	if line, _ := reader.(lookAheadLineReader).lookAhead().ReadLine(); !isTableLine(line) {
+		info.Alphabet = alphabet
+		_, err := info.alphabet()
+		return err
+	}
+	readCountedLine(reader, number, encodedSum)
+	alphabet, err := parseTable(func() ([]byte, error) {
+		return readCountedLine(reader, number, encodedSum)
+	})
+	if err != nil {
+		return err
+	}
+	info.Alphabet = alphabet
+	return nil
+}
+
+func readCountedLine(reader LineReader, number *int, encodedSum *bsdSum) ([]byte, error) {
+	line, err := reader.ReadLine()
+	if err != nil {
+		return nil, err
+	}
+	*number++
+	encodedSum.Write(line)
+	encodedSum.Write([]byte{'\n'})
+	return line, nil
+}
+
+func readTrailer(info *FileInfo, reader LineReader, number *int, encodedSum *bsdSum) error {
+	if !hasEndLine(info) {
+		return nil
+	}
+	line, err := readCountedLine(reader, number, encodedSum)
+	if err != nil {
+		return truncated(info, err)
+	}
+	return parseEnd(info, line)
+}
+
+func truncated(info *FileInfo, err error) error {
+	if err != io.EOF {
+		return err
+	}
+	info.truncated = true
+	return ErrTruncated
+}
+
+func decodePayloadLines(info *FileInfo, lines []payloadLine, out []byte, workers int, verify bool) ([]byte, error) {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if max := (len(lines) + minLinesPerWorker - 1) / minLinesPerWorker; workers > max {
+		workers = max
+	}
+	if workers <= 1 {
+		return decodePayloadChunk(info, lines, out, verify)
+	}
+
+	chunk := (len(lines) + workers - 1) / workers
+	chunks := (len(lines) + chunk - 1) / chunk
+	results := make([][]byte, chunks)
+	errs := make([]error, chunks)
+	var wg sync.WaitGroup
+	for i := 0; i < chunks; i++ {
+		end := (i + 1) * chunk
+		if end > len(lines) {
+			end = len(lines)
+		}
+		wg.Add(1)
+		go func(i int, lines []payloadLine) {
+			defer wg.Done()
+			results[i], errs[i] = decodePayloadChunk(info, lines, out, verify)
+		}(i, lines[i*chunk:end])
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return results[i], err
+		}
+	}
+	return out, nil
+}
+
+// decodePayloadChunk decodes the lines into their place in out, verifying their checksums if verify is set. If a
+// line fails to decode, the part of out preceding it is returned along with the error.
+func decodePayloadChunk(info *FileInfo, lines []payloadLine, out []byte, verify bool) ([]byte, error) {
+	for _, l := range lines {
+		decoded, err := parsePayloadLine(info, l.line, out[l.offset:l.offset:l.offset+l.length])
+		if err == nil && verify && !checkLineChecksum(info, l.line, decoded) {
+			err = &ChecksumError{Line: l.number}
+		}
+		if err != nil {
+			return out[:l.offset], err
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,106 @@
+package uu
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func decodeSequential(data []byte) (*FileInfo, []byte, error) {
+	reader := NewReader(NewSliceLineReader(data))
+	info, err := reader.FileInfo()
+	if err != nil {
+		return nil, nil, err
+	}
+	contents, err := ioutil.ReadAll(reader)
+	return info, contents, err
+}
+
+func assertDecodesLikeReader(t *testing.T, data []byte, workers int) {
+	expectedInfo, expected, expectedErr := decodeSequential(data)
+	info, contents, err := DecodeParallel(data, workers)
+	if expectedInfo != nil {
+		// Resolve the size estimate, which the Reader computes on demand
+		expectedInfo.Size()
+	}
+
+	assert.Equal(t, expectedInfo, info)
+	assert.Equal(t, expected, contents)
+	assert.Equal(t, expectedErr, err)
+}
+
+// largeTestData repeats the payload of testdata/test.uu enough times to spread over several workers
+func largeTestData() []byte {
+	data, err := ioutil.ReadFile("testdata/test.uu")
+	if err != nil {
+		panic(err)
+	}
+	lines := strings.Split(string(data), "\n")
+	header, payload, trailer := lines[0], lines[1:len(lines)-4], lines[len(lines)-3:]
+
+	var b bytes.Buffer
+	b.WriteString(header + "\n")
+	for i := 0; i < 2000; i++ {
+		b.WriteString(strings.Join(payload, "\n") + "\n")
+	}
+	b.WriteString(strings.Join(trailer, "\n"))
+	return b.Bytes()
+}
+
+func TestDecodeParallel_decodesFile(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/test.uu")
+	if err != nil {
+		panic(err)
+	}
+	expected, err := ioutil.ReadFile("testdata/test.bin")
+	if err != nil {
+		panic(err)
+	}
+
+	info, contents, err := DecodeParallel(data, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, &FileInfo{Encoding: UUEncoding, Mode: 0644, Name: "test.bin", decoded: 1024, complete: true}, info)
+	assert.Equal(t, expected, contents)
+}
+
+func TestDecodeParallel_largeInput(t *testing.T) {
+	data := largeTestData()
+
+	assertDecodesLikeReader(t, data, 0)
+	assertDecodesLikeReader(t, data, 1)
+	assertDecodesLikeReader(t, data, 3)
+	assertDecodesLikeReader(t, data, 64)
+}
+
+func TestDecodeParallel_errors(t *testing.T) {
+	inputs := []string{
+		"",
+		"begin-base63 000 hello.txt\n",
+		"begin 644 hello.txt\n",
+		"begin 644 hello.txt\n,2&5L;&\\@5V]R;&0*\n",
+		"begin 644 hello.txt\n,2&5L;&\\@5V]R;&0*\n`\n",
+		"begin 644 hello.txt\n,2&5L;&\\@5V]R;&0*\n`\nfnord\n",
+		"begin 644 hello.txt\n,2&5L;&\\@5V]R;&0*\n,2&5L\n`\nend\n",
+		"begin 644 hello.txt\n,2&5L;&\\@5V]R;&0*\nN\n`\nend\n",
+		"begin 644 hello.txt\n,2&5L;&\\@5V]R;&0*\n\n`\nend\n",
+		"begin-base64 644 hello.txt\nSGVsbG8g\nV29ybGQK\n====\n",
+		"begin-base64 644 hello.txt\nSGVsbG8g\nV29*bGQK\n====\n",
+		"begin-base64 644 hello.txt\nSGVsbG8g\nV29ybGQ\n====\n",
+		"begin 644 hello.txt\n#0V%T\n,2&5L;&\\@5V",
+		"begin-base64 644 hello.txt\nSGVsbG8g\nV29ybG",
+	}
+	for _, input := range inputs {
+		assertDecodesLikeReader(t, []byte(input), 2)
+	}
+}
+
+func TestDecodeParallel_largeInputError(t *testing.T) {
+	data := largeTestData()
+	i := len(data) / 2
+	i += bytes.IndexByte(data[i:], '\n') + 1
+	data[i] = 'N'
+
+	assertDecodesLikeReader(t, data, 4)
+}
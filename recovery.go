@@ -0,0 +1,73 @@
+package uu
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// RecoveryMode selects how a Reader handles payload lines that cannot be decoded
+type RecoveryMode int
+
+const (
+	// NoRecovery stops decoding at the first line that cannot be decoded
+	NoRecovery RecoveryMode = iota
+	// ZeroFillRecovery replaces a line that cannot be decoded with zero bytes of the length it declares, so
+	// the offsets of the following data are preserved
+	ZeroFillRecovery
+	// SkipRecovery leaves out lines that cannot be decoded
+	SkipRecovery
+)
+
+// Damage describes a payload line that could not be decoded
+type Damage struct {
+	// Line is the line number within the entry, where the header is line 1
+	Line int
+	// Offset is the position in the decoded data where the line belongs
+	Offset int64
+	// Length is the number of zero bytes written in place of the line, which is zero when skipping lines
+	Length int64
+	// Err is the error decoding the line
+	Err error
+}
+
+// DamageReport is returned by a Reader in place of io.EOF when it reaches the end of an entry in which lines
+// were replaced or skipped by a RecoveryMode. All data before it has been returned. If the input ends before the
+// end of such an entry, it is returned in place of ErrTruncated, with Truncated set.
+type DamageReport struct {
+	Damage []Damage
+	// Truncated reports whether the input ended before the end of the entry
+	Truncated bool
+}
+
+func (e *DamageReport) Error() string {
+	message := "Damaged input: " + strconv.Itoa(len(e.Damage)) + " lines could not be decoded"
+	if len(e.Damage) == 1 {
+		message = "Damaged input: 1 line could not be decoded"
+	}
+	if e.Truncated {
+		message += ", and the input was truncated"
+	}
+	return message
+}
+
+// declaredLength returns the number of bytes a damaged line would have decoded to. If the length byte is
+// damaged as well, it is inferred from the length of the line.
+func declaredLength(fileInfo *FileInfo, in []byte) int {
+	if fileInfo.Encoding == Base64Encoding {
+		return len(bytes.TrimRight(in, " \t\r")) / 4 * 3
+	}
+
+	if len(in) > 0 {
+		if n, err := outLengthFromByte(in[0]); err == nil && n > 0 {
+			return n
+		}
+	}
+	n := (len(in) - 1) / 4 * 3
+	switch {
+	case n < 0:
+		return 0
+	case n > maxLineBytes:
+		return maxLineBytes
+	}
+	return n
+}
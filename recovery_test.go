@@ -0,0 +1,117 @@
+package uu
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"testing"
+)
+
+const damagedInput = "begin 644 hello.txt\n" +
+	"#0V%T\n" +
+	"N0V%T\n" +
+	"#0V%T\n" +
+	"&86)C\n" +
+	"#0V%T\n" +
+	"`\n" +
+	"end\n"
+
+func TestUuReader_zeroFillRecovery(t *testing.T) {
+	reader := NewReaderWithOptions(NewSliceLineReader([]byte(damagedInput)), ReaderOptions{Recovery: ZeroFillRecovery})
+	contents, err := ioutil.ReadAll(reader)
+
+	assert.Equal(t, "Cat\x00\x00\x00Cat\x00\x00\x00\x00\x00\x00Cat", string(contents))
+	assert.Equal(t, &DamageReport{Damage: []Damage{
+		{Line: 3, Offset: 3, Length: 3, Err: newError("Invalid line length byte")},
+		{Line: 5, Offset: 9, Length: 6, Err: newError("Input line too short")},
+	}}, err)
+	assert.EqualError(t, err, "Damaged input: 2 lines could not be decoded")
+
+	fileInfo, _ := reader.FileInfo()
+	assert.True(t, fileInfo.Complete())
+	assert.Equal(t, int64(18), fileInfo.Size())
+}
+
+func TestUuReader_skipRecovery(t *testing.T) {
+	reader := NewReaderWithOptions(NewSliceLineReader([]byte(damagedInput)), ReaderOptions{Recovery: SkipRecovery})
+	contents, err := ioutil.ReadAll(reader)
+
+	assert.Equal(t, "CatCatCat", string(contents))
+	report, ok := err.(*DamageReport)
+	assert.True(t, ok)
+	assert.Equal(t, []Damage{
+		{Line: 3, Offset: 3, Length: 0, Err: newError("Invalid line length byte")},
+		{Line: 5, Offset: 6, Length: 0, Err: newError("Input line too short")},
+	}, report.Damage)
+}
+
+func TestUuReader_recoveryReadByte(t *testing.T) {
+	reader := NewReaderWithOptions(NewSliceLineReader([]byte(damagedInput)), ReaderOptions{Recovery: SkipRecovery})
+
+	var contents []byte
+	b, err := reader.ReadByte()
+	for err == nil {
+		contents = append(contents, b)
+		b, err = reader.ReadByte()
+	}
+	assert.Equal(t, "CatCatCat", string(contents))
+	assert.EqualError(t, err, "Damaged input: 2 lines could not be decoded")
+}
+
+func TestUuReader_recoveryBase64(t *testing.T) {
+	input := "begin-base64 644 hello.txt\nQ2F0\nQ2*0\nQ2F0\n====\n"
+	reader := NewReaderWithOptions(NewSliceLineReader([]byte(input)), ReaderOptions{Recovery: ZeroFillRecovery})
+	contents, err := ioutil.ReadAll(reader)
+
+	assert.Equal(t, "Cat\x00\x00\x00Cat", string(contents))
+	assert.EqualError(t, err, "Damaged input: 1 line could not be decoded")
+}
+
+func TestUuReader_recoveryUndamaged(t *testing.T) {
+	reader := NewReaderWithOptions(NewSliceLineReader([]byte("begin 644 hello.txt\n#0V%T\n`\nend\n")), ReaderOptions{Recovery: ZeroFillRecovery})
+	contents, err := ioutil.ReadAll(reader)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Cat", string(contents))
+}
+
+func TestUuReader_recoveryTruncated(t *testing.T) {
+	input := "begin 644 hello.txt\nN0V%T\n#0V%T\n,2&5L"
+	reader := NewReaderWithOptions(NewSliceLineReader([]byte(input)), ReaderOptions{Recovery: ZeroFillRecovery})
+	contents, err := ioutil.ReadAll(reader)
+
+	assert.Equal(t, &DamageReport{Damage: []Damage{
+		{Line: 2, Offset: 0, Length: 3, Err: newError("Invalid line length byte")},
+	}, Truncated: true}, err)
+	assert.EqualError(t, err, "Damaged input: 1 line could not be decoded, and the input was truncated")
+	assert.Equal(t, "\x00\x00\x00Cat", string(contents))
+
+	reader = NewReaderWithOptions(NewSliceLineReader([]byte(input)), ReaderOptions{Recovery: ZeroFillRecovery, AllowTruncated: true})
+	_, err = ioutil.ReadAll(reader)
+	assert.EqualError(t, err, "Damaged input: 1 line could not be decoded, and the input was truncated")
+}
+
+func TestUuReader_recoveryDamagedTerminatingLine(t *testing.T) {
+	for _, input := range []string{"begin 644 a\n#0V%T\n~\nend\n", "begin 644 a\n#0V%T\n~\r\nend\r\n"} {
+		reader := NewReaderWithOptions(NewSliceLineReader([]byte(input)), ReaderOptions{Recovery: ZeroFillRecovery})
+		contents, err := ioutil.ReadAll(reader)
+
+		assert.Equal(t, "Cat", string(contents))
+		assert.Equal(t, &DamageReport{Damage: []Damage{
+			{Line: 3, Offset: 3, Length: 0, Err: newError("Invalid line length byte")},
+		}}, err)
+		fileInfo, _ := reader.FileInfo()
+		assert.True(t, fileInfo.Complete())
+	}
+}
+
+func TestDeclaredLength(t *testing.T) {
+	fileInfo := &FileInfo{Encoding: UUEncoding}
+	assert.Equal(t, 3, declaredLength(fileInfo, []byte("#0V")))
+	assert.Equal(t, 45, declaredLength(fileInfo, []byte("N"+string(make([]byte, 60)))))
+	assert.Equal(t, 45, declaredLength(fileInfo, []byte("N"+string(make([]byte, 80)))))
+	assert.Equal(t, 6, declaredLength(fileInfo, []byte("\x0012345678")))
+	assert.Equal(t, 0, declaredLength(fileInfo, []byte("")))
+
+	fileInfo = &FileInfo{Encoding: Base64Encoding}
+	assert.Equal(t, 6, declaredLength(fileInfo, []byte("Q2*0Q2F0\r")))
+}
@@ -0,0 +1,32 @@
+package uu
+
+import (
+	"bytes"
+)
+
+// FindEntry skips lines of the LineReader up to the next valid header, and returns a Reader for the entry it
+// starts. It returns io.EOF if the LineReader has no more entries.
+//
+// This finds entries embedded in other text, such as the body of a mail message. A line is only taken as a header
+// if it parses as one, so text merely starting with "begin" is skipped.
+func FindEntry(reader LineReader, options ReaderOptions) (Reader, error) {
+	for {
+		line, err := reader.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.HasPrefix(line, []byte("begin")) {
+			continue
+		}
+
+		info, err := parseBegin(bytes.TrimSuffix(line, []byte{'\r'}))
+		if err != nil {
+			continue
+		}
+		r := &uuReader{reader: reader, options: options, scratch: make([]byte, 0, 45), line: 1}
+		if err = r.setInfo(info, line); err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+}
@@ -0,0 +1,38 @@
+package uu
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+func TestFindEntry(t *testing.T) {
+	input := "Hi,\n" +
+		"\n" +
+		"begin with this one:\n" +
+		catEntry +
+		"and then\n" +
+		"begin 600 hello.txt\r\n" +
+		",2&5L;&\\@5V]R;&0*\r\n" +
+		"`\r\n" +
+		"end\r\n" +
+		"Bye\n"
+	lr := NewSliceLineReader([]byte(input))
+
+	reader, err := FindEntry(lr, ReaderOptions{})
+	assert.Nil(t, err)
+	fileInfo, _ := reader.FileInfo()
+	assert.Equal(t, "cat.txt", fileInfo.Name)
+	contents, err := ioutil.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "Cat", string(contents))
+
+	reader, err = FindEntry(lr, ReaderOptions{})
+	assert.Nil(t, err)
+	fileInfo, _ = reader.FileInfo()
+	assert.Equal(t, "hello.txt", fileInfo.Name)
+
+	_, err = FindEntry(lr, ReaderOptions{})
+	assert.Equal(t, io.EOF, err)
+}
@@ -0,0 +1,68 @@
+package shar
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExtractOptions configures the behaviour of Archive.ExtractWithOptions
+type ExtractOptions struct {
+	// SpecialBits restores the setuid, setgid and sticky bits of files. They are dropped by default, as they let
+	// an untrusted archive create, for example, setuid executables.
+	SpecialBits bool
+}
+
+// Extract creates the files and directories of the archive below dir. Names that are absolute or lead outside of
+// dir are rejected, and existing files are not overwritten. Only the permission bits of the modes are restored.
+func (a *Archive) Extract(dir string) error {
+	return a.ExtractWithOptions(dir, ExtractOptions{})
+}
+
+// ExtractWithOptions creates the files and directories of the archive below dir like Extract, configured by
+// options
+func (a *Archive) ExtractWithOptions(dir string, options ExtractOptions) error {
+	for _, f := range a.Files {
+		name, err := safePath(dir, f.Name)
+		if err != nil {
+			return err
+		}
+		if f.Mode.IsDir() {
+			if err = os.MkdirAll(name, f.Mode.Perm()|0700); err != nil {
+				return err
+			}
+			continue
+		}
+		if err = os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			return err
+		}
+		if err = writeFile(name, f, options); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func safePath(dir string, name string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if name == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", errors.New("shar: unsafe file name: " + name)
+	}
+	return filepath.Join(dir, clean), nil
+}
+
+func writeFile(name string, f *File, options ExtractOptions) error {
+	out, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, f.Mode.Perm())
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(f.Contents)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && options.SpecialBits && f.Mode&(os.ModeSetuid|os.ModeSetgid|os.ModeSticky) != 0 {
+		err = os.Chmod(name, f.Mode&(os.ModePerm|os.ModeSetuid|os.ModeSetgid|os.ModeSticky))
+	}
+	return err
+}
@@ -0,0 +1,164 @@
+package shar
+
+import (
+	"bytes"
+	"strings"
+)
+
+// token is a word or an operator of a shell command line
+type token struct {
+	text string
+	// op is set for operators, such as "|", "&&" and ">"
+	op bool
+	// expands is set for words that contain a parameter expansion or command substitution, which are never
+	// performed
+	expands bool
+}
+
+func isOp(t token, ops ...string) bool {
+	if !t.op {
+		return false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenize splits a command line into words and operators, removing quotes and comments. It reports false if a
+// quote is not closed.
+func tokenize(line string) ([]token, bool) {
+	var tokens []token
+	i := 0
+	for i < len(line) {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '#':
+			return tokens, true
+		case strings.IndexByte("|&;<>()", c) != -1:
+			op, n := operator(line[i:])
+			tokens = append(tokens, token{text: op, op: true})
+			i += n
+		case c >= '0' && c <= '9' && redirectAfterDigits(line[i:]):
+			j := i
+			for line[j] >= '0' && line[j] <= '9' {
+				j++
+			}
+			op, n := operator(line[j:])
+			tokens = append(tokens, token{text: line[i:j] + op, op: true})
+			i = j + n
+		default:
+			t, n, ok := word(line[i:])
+			if !ok {
+				return nil, false
+			}
+			tokens = append(tokens, t)
+			i += n
+		}
+	}
+	return tokens, true
+}
+
+func redirectAfterDigits(s string) bool {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return i < len(s) && (s[i] == '>' || s[i] == '<')
+}
+
+// operator returns the operator at the start of s and its length, including the target of a file descriptor
+// duplication such as ">&1"
+func operator(s string) (string, int) {
+	for _, op := range []string{"<<-", "&&", "||", "<<", ">>", ">&", "<&"} {
+		if strings.HasPrefix(s, op) {
+			if op == ">&" || op == "<&" {
+				n := len(op)
+				for n < len(s) && (s[n] >= '0' && s[n] <= '9' || s[n] == '-') {
+					n++
+				}
+				return s[:n], n
+			}
+			return op, len(op)
+		}
+	}
+	return s[:1], 1
+}
+
+// word reads a word at the start of s, and returns it with the number of bytes consumed. It reports false if a
+// quote is not closed.
+func word(s string) (token, int, bool) {
+	var b bytes.Buffer
+	t := token{}
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || strings.IndexByte("|&;<>()", c) != -1:
+			t.text = b.String()
+			return t, i, true
+		case c == '\'':
+			j := strings.IndexByte(s[i+1:], '\'')
+			if j == -1 {
+				return t, 0, false
+			}
+			b.WriteString(s[i+1 : i+1+j])
+			i += j + 2
+		case c == '"':
+			i++
+			for {
+				if i >= len(s) {
+					return t, 0, false
+				}
+				c = s[i]
+				if c == '"' {
+					i++
+					break
+				}
+				if c == '\\' && i+1 < len(s) && strings.IndexByte("\"\\$`", s[i+1]) != -1 {
+					b.WriteByte(s[i+1])
+					i += 2
+					continue
+				}
+				if c == '$' || c == '`' {
+					t.expands = true
+				}
+				b.WriteByte(c)
+				i++
+			}
+		case c == '`':
+			j := strings.IndexByte(s[i+1:], '`')
+			if j == -1 {
+				return t, 0, false
+			}
+			b.WriteString(s[i : i+j+2])
+			t.expands = true
+			i += j + 2
+		case strings.HasPrefix(s[i:], "$("):
+			j := strings.IndexByte(s[i:], ')')
+			if j == -1 {
+				return t, 0, false
+			}
+			b.WriteString(s[i : i+j+1])
+			t.expands = true
+			i += j + 1
+		case c == '\\':
+			if i+1 < len(s) {
+				b.WriteByte(s[i+1])
+			}
+			i += 2
+		default:
+			if c == '$' || c == '`' {
+				t.expands = true
+			}
+			b.WriteByte(c)
+			i++
+		}
+	}
+	t.text = b.String()
+	return t, len(s), true
+}
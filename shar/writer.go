@@ -0,0 +1,175 @@
+package shar
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"github.com/gsson/uu"
+	"github.com/gsson/uu/internal/unixmode"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// sharDelimiter ends the here-documents written by the Writer. Every line of a file is prefixed with "X", so
+// no line of the contents can end a here-document early.
+const sharDelimiter = "SHAR_EOF"
+
+// WriterOptions controls the optional parts of the archives written by a Writer
+type WriterOptions struct {
+	// MD5Sums adds an md5sum check of each file
+	MD5Sums bool
+	// RestoreModes adds a chmod command restoring the mode of each file and directory
+	RestoreModes bool
+}
+
+// Writer writes shell archives that can be extracted with /bin/sh, in the format written by sharutils shar. Text
+// files are written as here-documents with an "X" prefix on each line, and other files are UU encoded and passed
+// to uudecode.
+type Writer struct {
+	writer  io.Writer
+	options WriterOptions
+	started bool
+	err     error
+}
+
+// NewWriter creates a Writer writing an archive to the provided io.Writer
+func NewWriter(writer io.Writer, options WriterOptions) *Writer {
+	return &Writer{writer: writer, options: options}
+}
+
+// WriteFile adds a file or directory to the archive. Existing files are not overwritten when the archive is
+// extracted. Names holding control characters, starting with a double quote or ending with a blank are rejected,
+// as they would be quoted or encoded in the header of a UU encoded file, which uudecode takes literally.
+func (w *Writer) WriteFile(f *File) error {
+	if w.err != nil {
+		return w.err
+	}
+	if !validName(f.Name) {
+		return errors.New("shar: invalid file name: " + strconv.Quote(f.Name))
+	}
+
+	var buf bytes.Buffer
+	if !w.started {
+		w.started = true
+		buf.WriteString("#!/bin/sh\n" +
+			"# This is a shell archive.\n" +
+			"# To extract the files from this archive, save it to some FILE, remove\n" +
+			"# everything before the '#!/bin/sh' line above, then type 'sh FILE'.\n" +
+			"#\n" +
+			"# Existing files will *not* be overwritten.\n" +
+			"#\n")
+	}
+
+	name := quote(f.Name)
+	if f.Mode.IsDir() {
+		buf.WriteString("if test ! -d " + name + "; then\n" +
+			"  echo " + quote("x - creating directory "+f.Name) + "\n" +
+			"  mkdir " + name + "\n" +
+			"fi\n")
+		w.writeMode(&buf, f)
+		return w.write(buf.Bytes())
+	}
+
+	buf.WriteString("# ============= " + f.Name + " ==============\n" +
+		"if test -f " + name + "; then\n" +
+		"  echo " + quote("x - SKIPPING "+f.Name+" (file already exists)") + "\n" +
+		"else\n")
+	if isText(f.Contents) {
+		buf.WriteString("  echo " + quote("x - extracting "+f.Name+" (text)") + "\n" +
+			"  sed 's/^X//' << '" + sharDelimiter + "' > " + name + "\n")
+		writePrefixed(&buf, f.Contents)
+	} else {
+		buf.WriteString("  echo " + quote("x - extracting "+f.Name+" (binary)") + "\n" +
+			"  sed 's/^X//' << '" + sharDelimiter + "' | uudecode\n")
+		var encoded bytes.Buffer
+		uw := uu.NewWriter(&encoded, uu.FileInfo{Encoding: uu.UUEncoding, Name: f.Name, Mode: f.Mode})
+		uw.Write(f.Contents)
+		if err := uw.Close(); err != nil {
+			return err
+		}
+		writePrefixed(&buf, encoded.Bytes())
+	}
+	buf.WriteString(sharDelimiter + "\n")
+
+	w.writeMode(&buf, f)
+	if w.options.MD5Sums {
+		sum := md5.Sum(f.Contents)
+		buf.WriteString("  md5sum -c << '" + sharDelimiter + "' >/dev/null 2>&1 || echo " +
+			quote(f.Name+": MD5 check failed") + "\n" +
+			hex.EncodeToString(sum[:]) + "  " + f.Name + "\n" +
+			sharDelimiter + "\n")
+	}
+	buf.WriteString("fi\n")
+	return w.write(buf.Bytes())
+}
+
+// Close writes the end of the archive. It does not close the underlying io.Writer.
+func (w *Writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if !w.started {
+		w.started = true
+		if err := w.write([]byte("#!/bin/sh\n")); err != nil {
+			return err
+		}
+	}
+	if err := w.write([]byte("exit 0\n")); err != nil {
+		return err
+	}
+	w.err = errors.New("shar: write on closed writer")
+	return nil
+}
+
+func (w *Writer) write(b []byte) error {
+	_, w.err = w.writer.Write(b)
+	return w.err
+}
+
+func (w *Writer) writeMode(buf *bytes.Buffer, f *File) {
+	if w.options.RestoreModes {
+		buf.WriteString("  chmod 0" + strconv.FormatUint(uint64(unixmode.Bits(f.Mode)), 8) + " " + quote(f.Name) + "\n")
+	}
+}
+
+// validName reports whether a name is written as is in the header of a UU encoded file
+func validName(name string) bool {
+	if name == "" || name[0] == '"' || strings.TrimRight(name, " \t") != name {
+		return false
+	}
+	return strings.IndexFunc(name, func(r rune) bool { return r < ' ' || r == 0x7f }) == -1
+}
+
+// quote quotes a word for the shell, so it is not expanded
+func quote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// isText reports whether the contents can be written as a here-document: printable UTF-8 text ending with a
+// newline
+func isText(contents []byte) bool {
+	if len(contents) > 0 && contents[len(contents)-1] != '\n' || !utf8.Valid(contents) {
+		return false
+	}
+	for _, c := range contents {
+		if c < ' ' && c != '\t' && c != '\n' && c != '\f' || c == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+func writePrefixed(buf *bytes.Buffer, contents []byte) {
+	for len(contents) > 0 {
+		i := bytes.IndexByte(contents, '\n') + 1
+		if i == 0 {
+			i = len(contents)
+		}
+		buf.WriteByte('X')
+		buf.Write(contents[:i])
+		contents = contents[i:]
+	}
+}
@@ -0,0 +1,69 @@
+package shar
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWriter_roundTrip(t *testing.T) {
+	files := []*File{
+		{Name: "docs", Mode: os.ModeDir | 0750},
+		{Name: "docs/hello.txt", Mode: 0640, Contents: []byte("Hello World\n$HOME is not expanded\nSHAR_EOF\n")},
+		{Name: "it's.txt", Mode: 0644, Contents: []byte{}},
+		{Name: "cat.bin", Mode: os.ModeSetuid | 0755, Contents: []byte("Cat\x00\xff")},
+		{Name: "no newline.txt", Mode: 0600, Contents: []byte("text")},
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, WriterOptions{MD5Sums: true, RestoreModes: true})
+	for _, f := range files {
+		assert.Nil(t, w.WriteFile(f))
+	}
+	assert.Nil(t, w.Close())
+
+	archive, err := Parse(&buf)
+	assert.Nil(t, err)
+	assert.Empty(t, archive.Issues)
+	assert.Equal(t, files, archive.Files)
+}
+
+func TestWriter_format(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, WriterOptions{})
+	assert.Nil(t, w.WriteFile(&File{Name: "a.txt", Mode: 0644, Contents: []byte("a\n")}))
+	assert.Nil(t, w.WriteFile(&File{Name: "b.bin", Mode: 0600, Contents: []byte("Cat")}))
+	assert.Nil(t, w.Close())
+
+	output := buf.String()
+	assert.Contains(t, output, "  sed 's/^X//' << 'SHAR_EOF' > 'a.txt'\nXa\nSHAR_EOF\n")
+	assert.Contains(t, output, "  sed 's/^X//' << 'SHAR_EOF' | uudecode\nXbegin 600 b.bin\nX#0V%T\nX`\nXend\nSHAR_EOF\n")
+	assert.False(t, strings.Contains(output, "md5sum"))
+	assert.False(t, strings.Contains(output, "chmod"))
+	assert.True(t, strings.HasSuffix(output, "exit 0\n"))
+}
+
+func TestWriter_invalidName(t *testing.T) {
+	w := NewWriter(&bytes.Buffer{}, WriterOptions{})
+	for _, name := range []string{"a\nb", "", "a\tb", "a\x7f", "a ", "a\t", `"a"`} {
+		assert.EqualError(t, w.WriteFile(&File{Name: name, Contents: []byte("Cat\x00")}), "shar: invalid file name: "+strconv.Quote(name))
+	}
+	assert.Nil(t, w.WriteFile(&File{Name: ` a "b"`, Contents: []byte("Cat\x00")}))
+}
+
+func TestWriter_specialBitsWithoutChmod(t *testing.T) {
+	files := []*File{{Name: "cat.bin", Mode: os.ModeSetuid | os.ModeSticky | 0755, Contents: []byte("Cat\x00")}}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, WriterOptions{})
+	assert.Nil(t, w.WriteFile(files[0]))
+	assert.Nil(t, w.Close())
+	assert.True(t, strings.Contains(buf.String(), "Xbegin 5755 cat.bin\n"))
+
+	archive, err := Parse(&buf)
+	assert.Nil(t, err)
+	assert.Equal(t, files, archive.Files)
+}
@@ -0,0 +1,95 @@
+package uu
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// sizeLinePrefix starts the line some encoders write after the trailer, holding the size of the decoded file
+const sizeLinePrefix = "size "
+
+var errVerifySizeUnsupported = newError("VerifySize needs a LineReader that can look ahead")
+
+// SizeError is returned when the size given by a size line following the trailer does not match the number of
+// bytes decoded
+type SizeError struct {
+	// Declared is the size given by the size line
+	Declared int64
+	// Decoded is the number of bytes decoded
+	Decoded int64
+}
+
+func (e *SizeError) Error() string {
+	return "Size mismatch: declared " + strconv.FormatInt(e.Declared, 10) + ", decoded " +
+		strconv.FormatInt(e.Decoded, 10)
+}
+
+// DeclaredSize returns the size given by a size line following the trailer, and whether it is known. It is known
+// before decoding if the LineReader can look ahead without consuming input, and otherwise once the size line has
+// been read.
+func (fi *FileInfo) DeclaredSize() (int64, bool) {
+	fi.scanAhead()
+	return fi.declared, fi.hasDeclared
+}
+
+func parseSizeLine(in []byte) (int64, bool) {
+	in = bytes.TrimRight(in, " \t\r")
+	if !bytes.HasPrefix(in, []byte(sizeLinePrefix)) {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(string(in[len(sizeLinePrefix):]), 10, 64)
+	if err != nil || size < 0 {
+		return 0, false
+	}
+	return size, true
+}
+
+// findSizeLine looks for a size line among the lines following the trailer, skipping a sum -r/size line. The
+// LineReader must be positioned after the trailer.
+func findSizeLine(fileInfo *FileInfo, reader LineReader) {
+	for i := 0; i < 2; i++ {
+		line, err := reader.ReadLine()
+		if err != nil {
+			return
+		}
+		if size, ok := parseSizeLine(line); ok {
+			fileInfo.declared, fileInfo.hasDeclared = size, true
+			return
+		}
+		if !bytes.HasPrefix(line, []byte(sumLinePrefix)) {
+			return
+		}
+	}
+}
+
+// isTrailerLine reports whether a line is a size or sum -r/size line, as may follow the trailer of an entry
+func isTrailerLine(in []byte) bool {
+	_, ok := parseSizeLine(in)
+	return ok || bytes.HasPrefix(in, []byte(sumLinePrefix))
+}
+
+// checkSizeLine records the size given by a size line and verifies it against the number of bytes decoded
+func checkSizeLine(fileInfo *FileInfo, in []byte) error {
+	size, ok := parseSizeLine(in)
+	if !ok {
+		return newError("Invalid size line")
+	}
+	fileInfo.declared, fileInfo.hasDeclared = size, true
+	if size != fileInfo.decoded {
+		return &SizeError{Declared: size, Decoded: fileInfo.decoded}
+	}
+	return nil
+}
+
+// readSizeLine consumes and verifies a size line following the trailer, if the LineReader can look ahead to find
+// one
+func readSizeLine(fileInfo *FileInfo, reader LineReader) error {
+	if !peekPrefix(reader, sizeLinePrefix) {
+		return nil
+	}
+	line, err := reader.ReadLine()
+	if err != nil {
+		return err
+	}
+	return checkSizeLine(fileInfo, line)
+}
@@ -0,0 +1,173 @@
+package uu
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+const sizedInput = "begin 644 cat.txt\n" +
+	"#0V%T\n" +
+	"`\n" +
+	"end\n" +
+	"size 3\n"
+
+func TestParseSizeLine(t *testing.T) {
+	size, ok := parseSizeLine([]byte("size 1024\r"))
+	assert.True(t, ok)
+	assert.Equal(t, int64(1024), size)
+
+	for _, line := range []string{"size", "size -1", "size x", "sizes 3"} {
+		_, ok = parseSizeLine([]byte(line))
+		assert.False(t, ok, line)
+	}
+}
+
+func TestUuReader_declaredSize(t *testing.T) {
+	reader := NewReader(NewSliceLineReader([]byte(sizedInput)))
+	fileInfo, err := reader.FileInfo()
+	assert.Nil(t, err)
+
+	size, ok := fileInfo.DeclaredSize()
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), size)
+	assert.Equal(t, int64(3), fileInfo.Size())
+}
+
+func TestUuReader_declaredSizeBeforeDecoding(t *testing.T) {
+	input := "begin 644 cat.txt\n#0V%T\n`\nend\nsum -r/size 16565/3 entire input file\nsize 1000\n"
+	reader := NewReader(NewSliceLineReader([]byte(input)))
+	fileInfo, _ := reader.FileInfo()
+	assert.Equal(t, int64(1000), fileInfo.Size())
+}
+
+func TestUuReader_verifySize(t *testing.T) {
+	readers := []LineReader{
+		NewSliceLineReader([]byte(sizedInput + sizedInput)),
+		NewBufioLineReader(bufio.NewReader(bytes.NewReader([]byte(sizedInput + sizedInput)))),
+	}
+	for _, lr := range readers {
+		for i := 0; i < 2; i++ {
+			reader := NewReaderWithOptions(lr, ReaderOptions{VerifySize: true})
+			contents, err := ioutil.ReadAll(reader)
+			assert.Nil(t, err)
+			assert.Equal(t, "Cat", string(contents))
+		}
+		_, err := lr.ReadLine()
+		assert.Equal(t, io.EOF, err)
+	}
+}
+
+func TestUuReader_verifySizeMismatch(t *testing.T) {
+	input := bytes.Replace([]byte(sizedInput), []byte("size 3"), []byte("size 4"), 1)
+	reader := NewReaderWithOptions(NewSliceLineReader(input), ReaderOptions{VerifySize: true})
+	_, err := ioutil.ReadAll(reader)
+	assert.Equal(t, &SizeError{Declared: 4, Decoded: 3}, err)
+	assert.EqualError(t, err, "Size mismatch: declared 4, decoded 3")
+
+	input = bytes.Replace([]byte(sizedInput), []byte("size 3"), []byte("size three"), 1)
+	reader = NewReaderWithOptions(NewSliceLineReader(input), ReaderOptions{VerifySize: true})
+	_, err = ioutil.ReadAll(reader)
+	assert.EqualError(t, err, "Invalid size line")
+}
+
+func TestUuReader_sizeLineNotConsumedByDefault(t *testing.T) {
+	lr := NewSliceLineReader([]byte(sizedInput))
+	_, err := ioutil.ReadAll(NewReader(lr))
+	assert.Nil(t, err)
+
+	line, err := lr.ReadLine()
+	assert.Nil(t, err)
+	assert.Equal(t, "size 3", string(line))
+}
+
+func TestDecodeParallel_declaredSize(t *testing.T) {
+	info, _, err := DecodeParallel([]byte(sizedInput), 2)
+	assert.Nil(t, err)
+	size, ok := info.DeclaredSize()
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), size)
+}
+
+func TestDecodingWriter_sizeLine(t *testing.T) {
+	var infos []*FileInfo
+	w := NewDecodingWriter(ioutil.Discard, func(info *FileInfo) io.Writer {
+		infos = append(infos, info)
+		return nil
+	})
+	assert.Nil(t, writeInChunks(w, []byte(sizedInput+sizedInput), 3))
+	assert.Nil(t, w.Close())
+	assert.Equal(t, 2, len(infos))
+	size, ok := infos[1].DeclaredSize()
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), size)
+
+	mismatched := bytes.Replace([]byte(sizedInput), []byte("size 3"), []byte("size 4"), 1)
+	w = NewDecodingWriter(ioutil.Discard, nil)
+	_, err := w.Write(mismatched)
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+
+	w = NewDecodingWriterWithOptions(ioutil.Discard, nil, ReaderOptions{VerifySize: true})
+	_, err = w.Write(mismatched)
+	assert.Equal(t, &SizeError{Declared: 4, Decoded: 3}, err)
+}
+
+func TestUuReader_verifySizeWithoutLookAhead(t *testing.T) {
+	reader := NewReaderWithOptions(NewReaderLineReader(bytes.NewReader([]byte(sizedInput))), ReaderOptions{VerifySize: true})
+	_, err := ioutil.ReadAll(reader)
+	assert.EqualError(t, err, "VerifySize needs a LineReader that can look ahead")
+}
+
+func TestReadEntry_skipsTrailerLines(t *testing.T) {
+	input := sizedInput + "sum -r/size 16565/3 entire input file\n" + sizedInput + "\nsize 3\n"
+	readers := []LineReader{
+		NewSliceLineReader([]byte(input)),
+		NewReaderLineReader(bytes.NewReader([]byte(input))),
+	}
+	for _, lr := range readers {
+		var names []string
+		for {
+			r, err := readEntry(lr, ReaderOptions{})
+			if err == io.EOF {
+				break
+			}
+			assert.Nil(t, err)
+			contents, err := ioutil.ReadAll(r)
+			assert.Nil(t, err)
+			assert.Equal(t, "Cat", string(contents))
+			names = append(names, r.info.Name)
+		}
+		assert.Equal(t, []string{"cat.txt", "cat.txt"}, names)
+	}
+
+	var out bytes.Buffer
+	w := NewDecodingWriter(&out, nil)
+	assert.Nil(t, writeInChunks(w, []byte("size 3\n"+input), 5))
+	assert.Nil(t, w.Close())
+	assert.Equal(t, "CatCat", out.String())
+}
+
+func TestUuReader_verifySizeWrapped(t *testing.T) {
+	mismatched := strings.Replace(sizedInput, "size 3", "size 4", 1)
+	quoted := "> " + strings.Replace(mismatched, "\n", "\n> ", 4)
+	readers := []LineReader{
+		NewDotUnstuffingLineReader(NewSliceLineReader([]byte(mismatched))),
+		NewQuoteStrippingLineReader(NewBufioLineReader(bufio.NewReader(strings.NewReader(quoted))), "> "),
+		NewFromUnmanglingLineReader(NewLimitedLineReader(NewSliceLineReader([]byte(mismatched)), Limits{})),
+	}
+	for _, lr := range readers {
+		reader := NewReaderWithOptions(lr, ReaderOptions{VerifySize: true})
+		_, err := ioutil.ReadAll(reader)
+		assert.Equal(t, &SizeError{Declared: 4, Decoded: 3}, err)
+	}
+
+	reader := NewReaderWithOptions(NewLimitedLineReader(NewReaderLineReader(strings.NewReader(mismatched)), Limits{}),
+		ReaderOptions{VerifySize: true})
+	_, err := ioutil.ReadAll(reader)
+	assert.EqualError(t, err, "VerifySize needs a LineReader that can look ahead")
+}
@@ -0,0 +1,49 @@
+package uu
+
+import (
+	"hash"
+	"sort"
+)
+
+// namedHash is a hash computed on the decoded bytes of an entry, under the name given in ReaderOptions.Hashes
+type namedHash struct {
+	name string
+	hash hash.Hash
+}
+
+// newHashes creates the hashes for an entry, sorted by name so they are updated in a stable order
+func newHashes(hashes map[string]func() hash.Hash) []namedHash {
+	if len(hashes) == 0 {
+		return nil
+	}
+	named := make([]namedHash, 0, len(hashes))
+	for name, newHash := range hashes {
+		named = append(named, namedHash{name: name, hash: newHash()})
+	}
+	sort.Slice(named, func(i, j int) bool { return named[i].name < named[j].name })
+	return named
+}
+
+func writeHashes(hashes []namedHash, decoded []byte) {
+	for _, h := range hashes {
+		h.hash.Write(decoded)
+	}
+}
+
+func sumHashes(hashes []namedHash) map[string][]byte {
+	if len(hashes) == 0 {
+		return nil
+	}
+	sums := make(map[string][]byte, len(hashes))
+	for _, h := range hashes {
+		sums[h.name] = h.hash.Sum(nil)
+	}
+	return sums
+}
+
+// Sum returns the result of the hash registered under name in ReaderOptions.Hashes, computed on the decoded bytes
+// of the entry. It is only available once the entry is complete, as reported by Complete.
+func (fi *FileInfo) Sum(name string) ([]byte, bool) {
+	sum, ok := fi.sums[name]
+	return sum, ok
+}
@@ -0,0 +1,72 @@
+package uu
+
+import (
+	"crypto/sha256"
+	"github.com/stretchr/testify/assert"
+	"hash"
+	"hash/crc32"
+	"io/ioutil"
+	"testing"
+)
+
+func TestUuReader_hashes(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/test.uu")
+	if err != nil {
+		panic(err)
+	}
+	expected, err := ioutil.ReadFile("testdata/test.bin")
+	if err != nil {
+		panic(err)
+	}
+
+	reader := NewReaderWithOptions(NewSliceLineReader(data), ReaderOptions{Hashes: map[string]func() hash.Hash{
+		"sha256": sha256.New,
+		"crc32":  func() hash.Hash { return crc32.NewIEEE() },
+	}})
+	fileInfo, _ := reader.FileInfo()
+	_, ok := fileInfo.Sum("sha256")
+	assert.False(t, ok)
+
+	_, err = ioutil.ReadAll(reader)
+	assert.Nil(t, err)
+
+	shaSum := sha256.Sum256(expected)
+	sum, ok := fileInfo.Sum("sha256")
+	assert.True(t, ok)
+	assert.Equal(t, shaSum[:], sum)
+
+	crc := crc32.NewIEEE()
+	crc.Write(expected)
+	sum, ok = fileInfo.Sum("crc32")
+	assert.True(t, ok)
+	assert.Equal(t, crc.Sum(nil), sum)
+
+	_, ok = fileInfo.Sum("md5")
+	assert.False(t, ok)
+}
+
+func TestUuReader_hashesPerEntry(t *testing.T) {
+	options := ReaderOptions{Hashes: map[string]func() hash.Hash{"sha256": sha256.New}}
+	lr := NewSliceLineReader([]byte("begin 644 hello.txt\n,2&5L;&\\@5V]R;&0*\n`\nend\nbegin 600 cat.txt\n#0V%T\n`\nend\n"))
+	for _, contents := range []string{"Hello World\n", "Cat"} {
+		reader := NewReaderWithOptions(lr, options)
+		_, err := ioutil.ReadAll(reader)
+		assert.Nil(t, err)
+
+		fileInfo, _ := reader.FileInfo()
+		expected := sha256.Sum256([]byte(contents))
+		sum, _ := fileInfo.Sum("sha256")
+		assert.Equal(t, expected[:], sum)
+	}
+}
+
+func TestUuReader_hashesTruncated(t *testing.T) {
+	options := ReaderOptions{Hashes: map[string]func() hash.Hash{"sha256": sha256.New}}
+	reader := NewReaderWithOptions(NewSliceLineReader([]byte("begin 644 cat.txt\n#0V%T\n")), options)
+	_, err := ioutil.ReadAll(reader)
+	assert.Equal(t, ErrTruncated, err)
+
+	fileInfo, _ := reader.FileInfo()
+	_, ok := fileInfo.Sum("sha256")
+	assert.False(t, ok)
+}
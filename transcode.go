@@ -0,0 +1,35 @@
+package uu
+
+import (
+	"io"
+)
+
+// Transcode re-encodes every entry read from the LineReader into the provided io.Writer using the encoding given,
+// keeping the name and mode of each entry. Entries are decoded and encoded a line at a time, so memory use does
+// not depend on their size. Classic entries are written with the standard alphabet, without a table line.
+func Transcode(dst io.Writer, src LineReader, to Encoding) error {
+	return TranscodeWithOptions(dst, src, to, ReaderOptions{})
+}
+
+// TranscodeWithOptions re-encodes the entries read from the LineReader like Transcode, reading them as configured
+// by options. Setting options.Alphabet to XXAlphabet reads XX encoded input.
+func TranscodeWithOptions(dst io.Writer, src LineReader, to Encoding, options ReaderOptions) error {
+	aw := NewArchiveWriter(dst)
+	err := convertEntries(src, options, func(r *uuReader) error {
+		w, err := aw.CreateEntry(FileInfo{
+			Encoding:    to,
+			Name:        r.info.Name,
+			Mode:        r.info.Mode,
+			EncodedName: r.info.EncodedName,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(w, r)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return aw.Close()
+}
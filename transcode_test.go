@@ -0,0 +1,69 @@
+package uu
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const base64ConvertInput = "begin-base64 644 hello.txt\n" +
+	"SGVsbG8gV29ybGQK\n" +
+	"====\n" +
+	"begin-base64 4755 bin/cat\n" +
+	"Q2F0\n" +
+	"====\n"
+
+const xxConvertInput = "begin 644 hello.txt\n" +
+	"AG4JgP4wUJqxmP4E8\n" +
+	"+\n" +
+	"end\n" +
+	"begin 4755 bin/cat\n" +
+	"1Eq3o\n" +
+	"+\n" +
+	"end\n"
+
+func transcode(t *testing.T, input string, to Encoding, options ReaderOptions) string {
+	var buf bytes.Buffer
+	assert.Nil(t, TranscodeWithOptions(&buf, NewReaderLineReader(bytes.NewReader([]byte(input))), to, options))
+	return buf.String()
+}
+
+func TestTranscode(t *testing.T) {
+	uuOutput := "begin 644 hello.txt\n" +
+		",2&5L;&\\@5V]R;&0*\n" +
+		"`\n" +
+		"end\n" +
+		"begin 4755 bin/cat\n" +
+		"#0V%T\n" +
+		"`\n" +
+		"end\n"
+	xx := ReaderOptions{Alphabet: XXAlphabet}
+
+	assert.Equal(t, base64ConvertInput, transcode(t, convertInput, Base64Encoding, ReaderOptions{}))
+	assert.Equal(t, xxConvertInput, transcode(t, convertInput, XXEncoding, ReaderOptions{}))
+	assert.Equal(t, uuOutput, transcode(t, base64ConvertInput, UUEncoding, ReaderOptions{}))
+	assert.Equal(t, uuOutput, transcode(t, xxConvertInput, UUEncoding, xx))
+	assert.Equal(t, base64ConvertInput, transcode(t, xxConvertInput, Base64Encoding, xx))
+}
+
+func TestTranscode_keepsEncodedName(t *testing.T) {
+	input := "begin-base64-encoded 644 aGVsbG8udHh0\n" +
+		"Q2F0\n" +
+		"====\n"
+	var buf bytes.Buffer
+	assert.Nil(t, Transcode(&buf, NewSliceLineReader([]byte(input)), UUEncoding))
+	assert.Equal(t, "begin-encoded 644 aGVsbG8udHh0\n#0V%T\n`\nend\n", buf.String())
+}
+
+func TestTranscode_truncated(t *testing.T) {
+	var buf bytes.Buffer
+	err := Transcode(&buf, NewSliceLineReader([]byte("begin 644 a\n#0V%T\n")), Base64Encoding)
+	assert.Equal(t, ErrTruncated, err)
+}
+
+func TestTranscode_sizeLines(t *testing.T) {
+	input := "begin 644 a\n#0V%T\n`\nend\nsize 3\nbegin 644 b\n#0V%T\n`\nend\nsize 3\n"
+	var buf bytes.Buffer
+	assert.Nil(t, Transcode(&buf, NewSliceLineReader([]byte(input)), Base64Encoding))
+	assert.Equal(t, "begin-base64 644 a\nQ2F0\n====\nbegin-base64 644 b\nQ2F0\n====\n", buf.String())
+}
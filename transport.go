@@ -0,0 +1,195 @@
+package uu
+
+import (
+	"bytes"
+)
+
+// The LineReaders in this file undo the changes made to encoded text by mail and news transports. They wrap
+// another LineReader and can be combined freely.
+
+type dotUnstuffingLineReader struct {
+	reader LineReader
+}
+
+// NewDotUnstuffingLineReader creates a LineReader that removes the extra dot SMTP and NNTP add to lines starting
+// with a dot ("..begin" becomes ".begin"). A line holding a single dot is passed on as is, so the terminating line
+// of a transfer must already have been removed.
+func NewDotUnstuffingLineReader(reader LineReader) LineReader {
+	return &dotUnstuffingLineReader{reader: reader}
+}
+
+func (r *dotUnstuffingLineReader) unwrap() LineReader {
+	return r.reader
+}
+
+func (r *dotUnstuffingLineReader) peek(n int) []byte {
+	b := peekLine(r.reader, n+1)
+	if bytes.HasPrefix(b, []byte("..")) {
+		b = b[1:]
+	}
+	return clip(b, n)
+}
+
+func (r *dotUnstuffingLineReader) ReadLine() ([]byte, error) {
+	line, err := r.reader.ReadLine()
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(line, []byte("..")) {
+		return line[1:], nil
+	}
+	return line, nil
+}
+
+type quoteStrippingLineReader struct {
+	reader LineReader
+	prefix []byte
+}
+
+// NewQuoteStrippingLineReader creates a LineReader that removes prefix, such as "> ", from the lines starting
+// with it. A line holding the prefix without its trailing whitespace becomes an empty line. Other lines are
+// passed on as is.
+func NewQuoteStrippingLineReader(reader LineReader, prefix string) LineReader {
+	return &quoteStrippingLineReader{reader: reader, prefix: []byte(prefix)}
+}
+
+func (r *quoteStrippingLineReader) unwrap() LineReader {
+	return r.reader
+}
+
+func (r *quoteStrippingLineReader) peek(n int) []byte {
+	return peekUnquoted(r.reader, r.prefix, n)
+}
+
+func (r *quoteStrippingLineReader) ReadLine() ([]byte, error) {
+	line, err := r.reader.ReadLine()
+	if err != nil {
+		return nil, err
+	}
+	stripped, _ := stripQuote(line, r.prefix)
+	return stripped, nil
+}
+
+// peekUnquoted returns up to n bytes of upcoming input from reader, with prefix removed if the next line starts
+// with it. A line holding only the prefix without its trailing whitespace is returned as is.
+func peekUnquoted(reader LineReader, prefix []byte, n int) []byte {
+	b := peekLine(reader, n+len(prefix))
+	if bytes.HasPrefix(b, prefix) {
+		b = b[len(prefix):]
+	}
+	return clip(b, n)
+}
+
+// stripQuote removes a quote prefix from a line, and reports whether the line was quoted
+func stripQuote(line []byte, prefix []byte) ([]byte, bool) {
+	if bytes.HasPrefix(line, prefix) {
+		return line[len(prefix):], true
+	}
+	if trimmed := bytes.TrimRight(prefix, " \t"); len(trimmed) > 0 && bytes.Equal(bytes.TrimRight(line, " \t\r"), trimmed) {
+		return line[len(line):], true
+	}
+	return line, false
+}
+
+type fromUnmanglingLineReader struct {
+	reader LineReader
+}
+
+// NewFromUnmanglingLineReader creates a LineReader that removes one ">" from lines starting with any number of
+// ">" followed by "From ", undoing the quoting of "From " lines by mbox files and some mail transports
+func NewFromUnmanglingLineReader(reader LineReader) LineReader {
+	return &fromUnmanglingLineReader{reader: reader}
+}
+
+func (r *fromUnmanglingLineReader) unwrap() LineReader {
+	return r.reader
+}
+
+func (r *fromUnmanglingLineReader) peek(n int) []byte {
+	b := peekLine(r.reader, n+1)
+	if quotes := len(b) - len(bytes.TrimLeft(b, ">")); quotes > 0 {
+		b = peekLine(r.reader, quotes+len("From ")+n)
+		if unquoted := bytes.TrimLeft(b, ">"); bytes.HasPrefix(unquoted, []byte("From ")) {
+			b = b[1:]
+		}
+	}
+	return clip(b, n)
+}
+
+func (r *fromUnmanglingLineReader) ReadLine() ([]byte, error) {
+	line, err := r.reader.ReadLine()
+	if err != nil {
+		return nil, err
+	}
+	if unquoted := bytes.TrimLeft(line, ">"); len(unquoted) < len(line) && bytes.HasPrefix(unquoted, []byte("From ")) {
+		return line[1:], nil
+	}
+	return line, nil
+}
+
+type quoteDetectingLineReader struct {
+	reader LineReader
+	prefix []byte
+	end    []byte
+}
+
+// NewQuoteDetectingLineReader creates a LineReader that finds headers quoted with a prefix made up of ">", "|",
+// spaces and tabs, such as in a forwarded or replied to message, and removes the prefix from the header and the
+// lines following it. The prefix is removed up to the trailer of the entry, or the first line that does not have
+// it. Lines outside quoted entries are passed on as is.
+func NewQuoteDetectingLineReader(reader LineReader) LineReader {
+	return &quoteDetectingLineReader{reader: reader}
+}
+
+func (r *quoteDetectingLineReader) unwrap() LineReader {
+	return r.reader
+}
+
+// peek removes the prefix of the entry being read, but does not detect quoted headers
+func (r *quoteDetectingLineReader) peek(n int) []byte {
+	if r.prefix != nil {
+		return peekUnquoted(r.reader, r.prefix, n)
+	}
+	return peekLine(r.reader, n)
+}
+
+func (r *quoteDetectingLineReader) ReadLine() ([]byte, error) {
+	line, err := r.reader.ReadLine()
+	if err != nil {
+		return nil, err
+	}
+
+	if r.prefix != nil {
+		stripped, quoted := stripQuote(line, r.prefix)
+		if !quoted {
+			r.prefix = nil
+			return line, nil
+		}
+		if bytes.Equal(bytes.TrimRight(stripped, " \t\r"), r.end) {
+			r.prefix = nil
+		}
+		return stripped, nil
+	}
+
+	if prefix := quotePrefix(line); prefix != nil {
+		info, err := parseBegin(bytes.TrimSuffix(line[len(prefix):], []byte{'\r'}))
+		if err == nil {
+			r.prefix, r.end = prefix, endMarker(info)
+			return line[len(prefix):], nil
+		}
+	}
+	return line, nil
+}
+
+// quotePrefix returns the quote prefix of a line holding a quoted header, or nil if it does not start with one
+func quotePrefix(line []byte) []byte {
+	i := bytes.Index(line, []byte("begin"))
+	if i < 1 {
+		return nil
+	}
+	prefix := line[:i]
+	if len(bytes.Trim(prefix, "> \t|")) != 0 || bytes.IndexAny(prefix, ">|") == -1 {
+		return nil
+	}
+	return prefix
+}
@@ -0,0 +1,79 @@
+package uu
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+func readLines(reader LineReader) []string {
+	var lines []string
+	for {
+		line, err := reader.ReadLine()
+		if err != nil {
+			return lines
+		}
+		lines = append(lines, string(line))
+	}
+}
+
+func TestDotUnstuffingLineReader(t *testing.T) {
+	reader := NewDotUnstuffingLineReader(NewSliceLineReader([]byte("..begin\n.\n...\nplain")))
+	assert.Equal(t, []string{".begin", ".", "..", "plain"}, readLines(reader))
+}
+
+func TestQuoteStrippingLineReader(t *testing.T) {
+	reader := NewQuoteStrippingLineReader(NewSliceLineReader([]byte("> begin\n>\n> > x\nplain\n>> y")), "> ")
+	assert.Equal(t, []string{"begin", "", "> x", "plain", ">> y"}, readLines(reader))
+}
+
+func TestFromUnmanglingLineReader(t *testing.T) {
+	reader := NewFromUnmanglingLineReader(NewSliceLineReader([]byte(">From x\n>>From y\n>Fro\nFrom z\n> From")))
+	assert.Equal(t, []string{"From x", ">From y", ">Fro", "From z", "> From"}, readLines(reader))
+}
+
+func TestQuoteDetectingLineReader(t *testing.T) {
+	input := "Forwarded:\n" +
+		"> begin to see\n" +
+		"> begin 644 cat.txt\n" +
+		"> #0V%T\n" +
+		"> `\n" +
+		"> end\n" +
+		"> and more\n" +
+		"|| begin 644 hello.txt\n" +
+		"|| ,2&5L;&\\@5V]R;&0*\n" +
+		"Bye\n"
+	reader := NewQuoteDetectingLineReader(NewSliceLineReader([]byte(input)))
+	assert.Equal(t, []string{
+		"Forwarded:",
+		"> begin to see",
+		"begin 644 cat.txt",
+		"#0V%T",
+		"`",
+		"end",
+		"> and more",
+		"begin 644 hello.txt",
+		",2&5L;&\\@5V]R;&0*",
+		"Bye",
+	}, readLines(reader))
+}
+
+func TestQuoteDetectingLineReader_decodes(t *testing.T) {
+	input := ">> begin 644 cat.txt\n>> #0V%T\n>> `\n>> end\n"
+	reader := NewReader(NewQuoteDetectingLineReader(NewSliceLineReader([]byte(input))))
+	contents, err := ioutil.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "Cat", string(contents))
+}
+
+func TestTransportLineReaders_combined(t *testing.T) {
+	input := "> ..begin 644 cat.txt\n> #0V%T\n> `\n> end\n"
+	lr := NewDotUnstuffingLineReader(NewQuoteStrippingLineReader(NewSliceLineReader([]byte(input)), "> "))
+	line, err := lr.ReadLine()
+	assert.Nil(t, err)
+	assert.Equal(t, ".begin 644 cat.txt", string(line))
+	readLines(lr)
+	_, err = lr.ReadLine()
+	assert.Equal(t, io.EOF, err)
+}
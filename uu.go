@@ -3,20 +3,30 @@ package uu
 
 import (
 	"bytes"
+	"encoding/base64"
+	"github.com/gsson/uu/internal/unixmode"
+	"hash"
 	"io"
 	"os"
 	"strconv"
 )
 
-type encoding int
+// Encoding identifies how the payload of an entry is encoded
+type Encoding int
 
 const (
-	// uuEncoding File is UU encoded
-	uuEncoding encoding = iota
-	// base64Encoding File is Base64 encoded
-	base64Encoding
+	// UUEncoding File is UU encoded
+	UUEncoding Encoding = iota
+	// Base64Encoding File is Base64 encoded
+	Base64Encoding
+	// XXEncoding File is XX encoded, with classic lines using XXAlphabet. The header is the same as for
+	// UUEncoding, so entries are never read as XXEncoding; read them with ReaderOptions.Alphabet set to XXAlphabet.
+	XXEncoding
 )
 
+// XXAlphabet holds the characters used by XXEncoding for the six-bit values
+const XXAlphabet = "+-0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
 type uuError struct {
 	message string
 }
@@ -25,11 +35,50 @@ func (e *uuError) Error() string {
 	return e.message
 }
 
+type truncatedError struct{}
+
+func (e *truncatedError) Error() string {
+	return "Truncated input"
+}
+
+func (e *truncatedError) Unwrap() error {
+	return io.ErrUnexpectedEOF
+}
+
+// ErrTruncated is returned when the input ends before the terminating line or the trailer of an entry. It
+// wraps io.ErrUnexpectedEOF.
+var ErrTruncated error = &truncatedError{}
+
+var (
+	errShortLine          = newError("Input line too short")
+	errShortBase64Line    = newError("Invalid base64 line length")
+	errUnsupportedOptions = newError("Unsupported ReaderOptions")
+)
+
 // FileInfo is the exposes meta-data about the encoded data
 type FileInfo struct {
-	encoding encoding
+	Encoding Encoding
 	Name     string
 	Mode     os.FileMode
+	// EncodedName is set if the header holds the name Base64 encoded (begin-encoded), as written by the
+	// sharutils uuencode -e option
+	EncodedName bool
+	// Alphabet, if not empty, holds the 64 characters used for the six-bit values of a classic entry in place
+	// of the standard ones, as given by a table line following the header
+	Alphabet string
+
+	table       *alphabet
+	rawName     []byte
+	decoded     int64
+	estimate    int64
+	estimated   bool
+	declared    int64
+	hasDeclared bool
+	// ahead reads the lines following the header, for estimating the size when it is first asked for
+	ahead     LineReader
+	sums      map[string][]byte
+	complete  bool
+	truncated bool
 }
 
 // The Reader interface expose the UU functionality
@@ -39,16 +88,85 @@ type Reader interface {
 	FileInfo() (*FileInfo, error)
 }
 
+// ReaderOptions configures the behaviour of a Reader created with NewReaderWithOptions
+type ReaderOptions struct {
+	// AllowTruncated makes a Reader end a truncated entry with io.EOF rather than ErrTruncated, after returning
+	// all the complete lines preceding the cut. FileInfo.Truncated reports whether the entry was truncated.
+	AllowTruncated bool
+	// Recovery selects how payload lines that cannot be decoded are handled. If any lines are replaced or
+	// skipped, the Reader ends the entry with a *DamageReport rather than io.EOF, or ErrTruncated if the input
+	// ends before the end of the entry. A trailer line following a damaged terminating line ends the payload.
+	Recovery RecoveryMode
+	// Alphabet, if not empty, is used for the classic entries that are not preceded by a table line. It must
+	// hold 64 distinct characters.
+	Alphabet string
+	// VerifySize makes a Reader consume a size line following the trailer and return a *SizeError if it does
+	// not match the number of bytes decoded. The line is only found if the LineReader can look ahead without
+	// consuming input, as the ones created by NewSliceLineReader and NewBufioLineReader, and the LineReaders of
+	// this package wrapping them; with any other LineReader, reading the header returns an error.
+	VerifySize bool
+	// VerifyLineChecksums makes classic payload lines holding one character more than their payload be taken as
+	// having a trailing checksum character, as written with WriterOptions.LineChecksums, and verified. A mismatch
+	// returns a *ChecksumError. Otherwise the extra character is ignored, as is padding.
+	VerifyLineChecksums bool
+	// Hashes holds constructors for hashes, such as sha256.New or crc32.NewIEEE, that are computed on the
+	// decoded bytes of each entry. FileInfo.Sum returns the results by name once the entry is complete.
+	Hashes map[string]func() hash.Hash
+}
+
 type uuReader struct {
 	reader  LineReader
+	options ReaderOptions
 	scratch []byte
 	info    *FileInfo
 	err     error
+	line    int
+	peeked  []byte
+	peekErr error
+	peek    bool
+	damage  *DamageReport
+	std     []byte
+	limiter *limitedLineReader
+
+	decodedSum bsdSum
+	encodedSum bsdSum
+	hashes     []namedHash
 }
 
 // NewReader creates a new Reader for decoding an UU encoded chunk from the provided LineReader
 func NewReader(reader LineReader) Reader {
-	return &uuReader{reader: reader, info: nil, err: nil, scratch: make([]byte, 0, 45)}
+	return NewReaderWithOptions(reader, ReaderOptions{})
+}
+
+// NewReaderWithOptions creates a new Reader for decoding an UU encoded chunk from the provided LineReader,
+// configured by options
+func NewReaderWithOptions(reader LineReader, options ReaderOptions) Reader {
+	return &uuReader{reader: reader, options: options, info: nil, err: nil, scratch: make([]byte, 0, 45)}
+}
+
+// readEntry reads the header of the next entry from the LineReader, skipping any blank lines preceding it, and
+// any size and sum -r/size lines following the previous entry. It returns io.EOF if the LineReader has no more
+// entries.
+func readEntry(reader LineReader, options ReaderOptions) (*uuReader, error) {
+	for {
+		line, err := reader.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(line)) == 0 || isTrailerLine(line) {
+			continue
+		}
+
+		info, err := parseBegin(line)
+		if err != nil {
+			return nil, err
+		}
+		r := &uuReader{reader: reader, options: options, info: nil, err: nil, scratch: make([]byte, 0, 45), line: 1}
+		if err = r.setInfo(info, line); err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
 }
 
 func (r *uuReader) nextOutByte() byte {
@@ -72,7 +190,9 @@ func (r *uuReader) ReadByte() (byte, error) {
 		r.readInfo()
 	}
 
-	r.readLine()
+	for len(r.scratch) == 0 && r.err == nil {
+		r.readLine()
+	}
 
 	if r.err != nil {
 		return 0, r.err
@@ -90,7 +210,9 @@ func (r *uuReader) Read(b []byte) (int, error) {
 		r.readInfo()
 	}
 
-	r.readLine()
+	for len(r.scratch) == 0 && r.err == nil {
+		r.readLine()
+	}
 
 	if r.err != nil {
 		return 0, r.err
@@ -103,33 +225,201 @@ func (r *uuReader) readLine() {
 	if r.err != nil {
 		return
 	}
-	line, err := r.reader.ReadLine()
+	line, err := r.nextLine()
 	if err != nil {
-		r.err = err
+		r.err = r.truncated(err)
 		return
 	}
 
-	r.scratch, err = parsePayloadLine(r.info, line, r.scratch)
-	if err != nil {
+	raw := line
+	std, err := standardLine(r.info, line, &r.std)
+	if err == nil {
+		line = std
+		r.scratch, err = parsePayloadLine(r.info, line, r.scratch)
+	}
+	if err == nil && r.options.VerifyLineChecksums && !checkLineChecksum(r.info, line, r.scratch) {
+		r.scratch, err = nil, &ChecksumError{Line: r.line}
+	}
+	if err == io.EOF {
 		r.err = err
-		if err == io.EOF {
-			r.readEnd()
+		r.readEnd()
+		return
+	}
+	if err != nil {
+		if isShortLine(err) && r.atEOF() {
+			// The input was cut in the middle of the last line
+			r.err = r.truncated(io.EOF)
+			return
+		}
+		if r.options.Recovery == NoRecovery {
+			r.err = err
+			return
+		}
+		if hasEndLine(r.info) && bytes.Equal(bytes.TrimSuffix(raw, []byte{'\r'}), endMarker(r.info)) {
+			// The terminating line was damaged, and this is the trailer following it
+			r.err = io.EOF
+			r.endEntry()
+			return
+		}
+		r.scratch = r.recoverLine(line, err)
+	}
+	if r.limiter != nil {
+		if err = r.limiter.decoded(len(r.scratch)); err != nil {
+			r.scratch = r.scratch[:0]
+			r.err = err
+			return
 		}
 	}
+	r.info.decoded += int64(len(r.scratch))
+	r.decodedSum.Write(r.scratch)
+	writeHashes(r.hashes, r.scratch)
 }
 
 func (r *uuReader) readEnd() {
-	line, err := r.reader.ReadLine()
-	if err != nil {
-		r.err = err
-		return
+	if hasEndLine(r.info) {
+		line, err := r.nextLine()
+		if err != nil {
+			r.err = r.truncated(err)
+			return
+		}
+
+		err = parseEnd(r.info, line)
+		if err != nil {
+			r.err = err
+			return
+		}
 	}
+	r.endEntry()
+}
 
-	err = parseEnd(r.info, line)
-	if err != nil {
+// endEntry completes the entry once its trailer has been read
+func (r *uuReader) endEntry() {
+	r.info.complete = true
+	r.info.sums = sumHashes(r.hashes)
+	if r.info.ahead != nil {
+		// The size no longer needs estimating, but a size line may follow
+		r.info.ahead = nil
+		if l, ok := r.reader.(lookAheadLineReader); ok && !r.peek {
+			findSizeLine(r.info, l.lookAhead())
+		}
+	}
+	if err := r.readTrailers(); err != nil {
 		r.err = err
-		return
 	}
+	if r.damage != nil {
+		r.err = r.damage
+	}
+}
+
+// readTrailers consumes the sum -r/size line, and the size line if enabled by the ReaderOptions, following the
+// trailer in either order
+func (r *uuReader) readTrailers() error {
+	number := r.line
+	sum, size := false, false
+	for {
+		switch {
+		case !sum && peekPrefix(r.reader, sumLinePrefix):
+			sum, number = true, number+1
+			if err := readSumLine(r.reader, number, &r.decodedSum, &r.encodedSum); err != nil {
+				return err
+			}
+		case !size && r.options.VerifySize && peekPrefix(r.reader, sizeLinePrefix):
+			size, number = true, number+1
+			if err := readSizeLine(r.info, r.reader); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+func (r *uuReader) nextLine() ([]byte, error) {
+	line := r.peeked
+	if r.peek {
+		r.peek = false
+		if r.peekErr != nil {
+			return nil, r.peekErr
+		}
+	} else {
+		var err error
+		if line, err = r.reader.ReadLine(); err != nil {
+			return nil, err
+		}
+	}
+	r.countLine(line)
+	return line, nil
+}
+
+func (r *uuReader) countLine(line []byte) {
+	r.line++
+	r.encodedSum.Write(line)
+	r.encodedSum.Write([]byte{'\n'})
+}
+
+// atEOF reports whether the LineReader has no more lines, keeping the next line for nextLine otherwise
+func (r *uuReader) atEOF() bool {
+	line, err := r.reader.ReadLine()
+	if err == io.EOF {
+		return true
+	}
+	if err == nil {
+		r.peeked, r.peekErr, r.peek = line, nil, true
+	}
+	return false
+}
+
+// readTable reads the alphabet of the entry if the header is followed by a table line, keeping the next line for
+// nextLine otherwise. Without a table line, the alphabet of the ReaderOptions is used.
+func (r *uuReader) readTable() error {
+	if r.info.Encoding != UUEncoding {
+		return nil
+	}
+	if p := peeker(r.reader); p == nil || bytes.HasPrefix(p.peek(len(tableMarker)), []byte(tableMarker)) {
+		line, err := r.reader.ReadLine()
+		if err != nil || !isTableLine(line) {
+			r.peeked, r.peekErr, r.peek = line, err, true
+		} else {
+			r.countLine(line)
+			alphabet, err := parseTable(r.nextLine)
+			if err != nil {
+				return err
+			}
+			r.info.Alphabet = alphabet
+			return nil
+		}
+	}
+	r.info.Alphabet = r.options.Alphabet
+	_, err := r.info.alphabet()
+	return err
+}
+
+func (r *uuReader) recoverLine(line []byte, err error) []byte {
+	n := 0
+	if r.options.Recovery == ZeroFillRecovery {
+		n = declaredLength(r.info, line)
+	}
+	if r.damage == nil {
+		r.damage = &DamageReport{}
+	}
+	r.damage.Damage = append(r.damage.Damage, Damage{Line: r.line, Offset: r.info.decoded, Length: int64(n), Err: err})
+	return append(r.scratch[:0], make([]byte, n)...)
+}
+
+// truncated maps an io.EOF from the LineReader in the middle of an entry to the error returned to the caller
+func (r *uuReader) truncated(err error) error {
+	if err != io.EOF {
+		return err
+	}
+	r.info.truncated = true
+	if r.damage != nil {
+		r.damage.Truncated = true
+		return r.damage
+	}
+	if r.options.AllowTruncated {
+		return io.EOF
+	}
+	return ErrTruncated
 }
 
 func (r *uuReader) readInfo() {
@@ -144,7 +434,31 @@ func (r *uuReader) readInfo() {
 		return
 	}
 
+	r.line = 1
+	r.err = r.setInfo(info, line)
+}
+
+func (r *uuReader) setInfo(info *FileInfo, header []byte) error {
+	if r.options.VerifySize && peeker(r.reader) == nil {
+		return errVerifySizeUnsupported
+	}
+	r.limiter = findLimiter(r.reader)
+	if r.limiter != nil {
+		if err := r.limiter.beginEntry(info); err != nil {
+			return err
+		}
+	}
 	r.info = info
+	r.hashes = newHashes(r.options.Hashes)
+	r.encodedSum.Write(header)
+	r.encodedSum.Write([]byte{'\n'})
+	if err := r.readTable(); err != nil {
+		return err
+	}
+	if l, ok := r.reader.(lookAheadLineReader); ok && !r.peek {
+		info.ahead = l.lookAhead()
+	}
+	return nil
 }
 
 func (r *uuReader) FileInfo() (*FileInfo, error) {
@@ -208,22 +522,40 @@ func decodeBlocks(in []byte, out []byte, inLength int) ([]byte, int) {
 	return out, i
 }
 
+// Unix file type bits, as written in the header by some encoders
+const (
+	unixTypeMask = 0170000
+	unixRegular  = 0100000
+)
+
+// fileMode parses the octal Unix mode of the header. A regular file type, as written by some encoders, is
+// accepted and ignored.
 func fileMode(mode []byte) (os.FileMode, error) {
 	v, err := strconv.ParseUint(string(mode), 8, 32)
 	if err != nil {
 		return os.FileMode(0), newError("Failed to parse file mode: " + err.Error())
 	}
-	return os.FileMode(v), nil
+	if v&unixTypeMask == unixRegular {
+		v &^= unixTypeMask
+	}
+	if v&^unixmode.Mask != 0 {
+		return os.FileMode(0), newError("File mode out of range: " + string(mode))
+	}
+	return unixmode.FileMode(uint32(v)), nil
 }
 
-func fileEncoding(begin []byte) (encoding, error) {
+func fileEncoding(begin []byte) (Encoding, bool, error) {
 	switch string(begin) {
 	case "begin":
-		return uuEncoding, nil
+		return UUEncoding, false, nil
 	case "begin-base64":
-		return base64Encoding, nil
+		return Base64Encoding, false, nil
+	case "begin-encoded":
+		return UUEncoding, true, nil
+	case "begin-base64-encoded":
+		return Base64Encoding, true, nil
 	default:
-		return 0, newError("Invalid header")
+		return 0, false, newError("Invalid header")
 	}
 }
 
@@ -240,9 +572,7 @@ func parseBegin(in []byte) (*FileInfo, error) {
 	}
 	mode, tail := tail[:imode], tail[imode+1:]
 
-	file := string(tail)
-
-	encoding, err := fileEncoding(begin)
+	encoding, encodedName, err := fileEncoding(begin)
 	if err != nil {
 		return nil, err
 	}
@@ -251,7 +581,16 @@ func parseBegin(in []byte) (*FileInfo, error) {
 		return nil, err
 	}
 
-	return &FileInfo{encoding: encoding, Mode: fileMode, Name: file}, nil
+	if encodedName {
+		file, rawFile, err := parseEncodedName(tail)
+		if err != nil {
+			return nil, err
+		}
+		return &FileInfo{Encoding: encoding, Mode: fileMode, Name: file, EncodedName: true, rawName: rawFile}, nil
+	}
+
+	file, rawFile := parseName(tail)
+	return &FileInfo{Encoding: encoding, Mode: fileMode, Name: file, rawName: rawFile}, nil
 }
 
 func parseEnd(fileInfo *FileInfo, in []byte) error {
@@ -262,29 +601,73 @@ func parseEnd(fileInfo *FileInfo, in []byte) error {
 }
 
 func endMarker(fileInfo *FileInfo) []byte {
-	switch fileInfo.encoding {
-	case uuEncoding:
+	switch fileInfo.Encoding {
+	case UUEncoding, XXEncoding:
 		return []byte("end")
-	case base64Encoding:
+	case Base64Encoding:
 		return []byte("====")
 	}
 	panic("Invalid encoding")
 }
 
-func parsePayloadLine(fileInfo *FileInfo, in []byte, out []byte) ([]byte, error) {
+func isShortLine(err error) bool {
+	return err == errShortLine || err == errShortBase64Line
+}
+
+// hasEndLine reports whether the terminating payload line is followed by a separate trailer line
+func hasEndLine(fileInfo *FileInfo) bool {
+	return fileInfo.Encoding != Base64Encoding
+}
+
+// payloadLength validates a payload line and returns the number of bytes it decodes to, or io.EOF if it is the
+// line terminating the payload
+func payloadLength(fileInfo *FileInfo, in []byte) (int, error) {
+	if fileInfo.Encoding == Base64Encoding {
+		return base64PayloadLength(fileInfo, in)
+	}
+
+	if len(in) == 0 {
+		return 0, errShortLine
+	}
 	outLength, err := outLengthFromByte(in[0])
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 	if outLength == 0 {
-		return nil, io.EOF
+		return 0, io.EOF
+	}
+	if len(in) < inLengthFromOutLength(outLength)+1 { // + 1 for length byte
+		return 0, errShortLine
 	}
+	return outLength, nil
+}
 
-	inLength := inLengthFromOutLength(outLength)
-	if len(in) < inLength+1 { // + 1 for length byte
-		return nil, newError("Input line too short")
+func base64PayloadLength(fileInfo *FileInfo, in []byte) (int, error) {
+	in = bytes.TrimRight(in, " \t\r")
+	if bytes.Equal(in, endMarker(fileInfo)) {
+		return 0, io.EOF
+	}
+	if len(in) == 0 || len(in)%4 != 0 {
+		return 0, errShortBase64Line
+	}
+	padding := 0
+	for i := len(in) - 1; i >= len(in)-2 && in[i] == '='; i-- {
+		padding++
+	}
+	return len(in)/4*3 - padding, nil
+}
+
+func parsePayloadLine(fileInfo *FileInfo, in []byte, out []byte) ([]byte, error) {
+	outLength, err := payloadLength(fileInfo, in)
+	if err != nil {
+		return nil, err
+	}
+
+	if fileInfo.Encoding == Base64Encoding {
+		return decodeBase64Line(in, out, outLength)
 	}
 
+	inLength := inLengthFromOutLength(outLength)
 	payload := in[1:]
 	var i int
 
@@ -301,3 +684,14 @@ func parsePayloadLine(fileInfo *FileInfo, in []byte, out []byte) ([]byte, error)
 
 	return out, nil
 }
+
+func decodeBase64Line(in []byte, out []byte, outLength int) ([]byte, error) {
+	start := len(out)
+	out = append(out, make([]byte, outLength)...)
+
+	_, err := base64.StdEncoding.Decode(out[start:], bytes.TrimRight(in, " \t\r"))
+	if err != nil {
+		return nil, newError("Invalid base64 data: " + err.Error())
+	}
+	return out, nil
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -83,33 +84,81 @@ func TestParsePayloadLine(t *testing.T) {
 
 	assertPayloadLineFails(t, "%80``\n", newError("Input line too short"))
 	assertPayloadLineFails(t, "N\n", newError("Invalid line length byte"))
+	assertPayloadLineFails(t, "", newError("Input line too short"))
+}
+
+func TestParsePayloadLine_base64(t *testing.T) {
+	fileInfo := FileInfo{Encoding: Base64Encoding, Mode: os.FileMode(0), Name: "hello.txt"}
+
+	out, err := parsePayloadLine(&fileInfo, []byte("Q2F0"), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("Cat"), out)
+
+	out, err = parsePayloadLine(&fileInfo, []byte("SGVsbG8gV29ybGQK\r"), []byte("> "))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("> Hello World\n"), out)
+
+	out, err = parsePayloadLine(&fileInfo, []byte("YQ=="), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("a"), out)
+
+	_, err = parsePayloadLine(&fileInfo, []byte("===="), nil)
+	assert.Equal(t, io.EOF, err)
+
+	_, err = parsePayloadLine(&fileInfo, []byte("Q2F"), nil)
+	assert.EqualError(t, err, "Invalid base64 line length")
+
+	_, err = parsePayloadLine(&fileInfo, []byte("Q2*0"), nil)
+	assert.EqualError(t, err, "Invalid base64 data: illegal base64 data at input byte 2")
 }
 
 func TestParseBeginLine(t *testing.T) {
-	assertBeginLineParsed(t, "begin 000 hello.txt", FileInfo{encoding: uuEncoding, Mode: os.FileMode(0), Name: "hello.txt"})
-	assertBeginLineParsed(t, "begin-base64 000 hello.txt", FileInfo{encoding: base64Encoding, Mode: os.FileMode(0), Name: "hello.txt"})
+	assertBeginLineParsed(t, "begin 000 hello.txt", FileInfo{Encoding: UUEncoding, Mode: os.FileMode(0), Name: "hello.txt"})
+	assertBeginLineParsed(t, "begin-base64 000 hello.txt", FileInfo{Encoding: Base64Encoding, Mode: os.FileMode(0), Name: "hello.txt"})
 
 	assertBeginLineFails(t, "begin-base63 000 hello.txt", "Invalid header")
 	assertBeginLineFails(t, "", "Invalid header")
 	assertBeginLineFails(t, "begi", "Invalid header")
 	assertBeginLineFails(t, "begin aaa hello.txt", "Failed to parse file mode: strconv.ParseUint: parsing \"aaa\": invalid syntax")
 	assertBeginLineFails(t, "begin 000", "Invalid header")
+	assertBeginLineFails(t, "begin 99999 hello.txt", "Failed to parse file mode: strconv.ParseUint: parsing \"99999\": invalid syntax")
+	assertBeginLineFails(t, "begin 177777 hello.txt", "File mode out of range: 177777")
+	assertBeginLineFails(t, "begin 40755 hello", "File mode out of range: 40755")
+}
+
+func TestParseBeginLine_modes(t *testing.T) {
+	assertBeginLineParsed(t, "begin 644 hello.txt", FileInfo{Encoding: UUEncoding, Mode: os.FileMode(0644), Name: "hello.txt"})
+	assertBeginLineParsed(t, "begin 4755 tool", FileInfo{Encoding: UUEncoding, Mode: os.ModeSetuid | 0755, Name: "tool"})
+	assertBeginLineParsed(t, "begin 2750 tool", FileInfo{Encoding: UUEncoding, Mode: os.ModeSetgid | 0750, Name: "tool"})
+	assertBeginLineParsed(t, "begin 1777 tmp", FileInfo{Encoding: UUEncoding, Mode: os.ModeSticky | 0777, Name: "tmp"})
+	assertBeginLineParsed(t, "begin 7000 all", FileInfo{Encoding: UUEncoding, Mode: os.ModeSetuid | os.ModeSetgid | os.ModeSticky, Name: "all"})
+	assertBeginLineParsed(t, "begin 100644 hello.txt", FileInfo{Encoding: UUEncoding, Mode: os.FileMode(0644), Name: "hello.txt"})
 }
 
 func TestParseEndLine(t *testing.T) {
-	assertEndLineParsed(t, "end", FileInfo{encoding: uuEncoding, Mode: os.FileMode(0), Name: "hello.txt"})
-	assertEndLineParsed(t, "====", FileInfo{encoding: base64Encoding, Mode: os.FileMode(0), Name: "hello.txt"})
+	assertEndLineParsed(t, "end", FileInfo{Encoding: UUEncoding, Mode: os.FileMode(0), Name: "hello.txt"})
+	assertEndLineParsed(t, "====", FileInfo{Encoding: Base64Encoding, Mode: os.FileMode(0), Name: "hello.txt"})
 
-	assertEndLineFails(t, "====", FileInfo{encoding: uuEncoding, Mode: os.FileMode(0), Name: "hello.txt"})
-	assertEndLineFails(t, "end", FileInfo{encoding: base64Encoding, Mode: os.FileMode(0), Name: "hello.txt"})
-	assertEndLineFails(t, "fnord", FileInfo{encoding: uuEncoding, Mode: os.FileMode(0), Name: "hello.txt"})
-	assertEndLineFails(t, "fnord", FileInfo{encoding: base64Encoding, Mode: os.FileMode(0), Name: "hello.txt"})
+	assertEndLineFails(t, "====", FileInfo{Encoding: UUEncoding, Mode: os.FileMode(0), Name: "hello.txt"})
+	assertEndLineFails(t, "end", FileInfo{Encoding: Base64Encoding, Mode: os.FileMode(0), Name: "hello.txt"})
+	assertEndLineFails(t, "fnord", FileInfo{Encoding: UUEncoding, Mode: os.FileMode(0), Name: "hello.txt"})
+	assertEndLineFails(t, "fnord", FileInfo{Encoding: Base64Encoding, Mode: os.FileMode(0), Name: "hello.txt"})
 }
 
 func TestUuReader_decodesFile(t *testing.T) {
 	assertDecodes(t, "test.uu", 0644, "test.bin")
 }
 
+func TestUuReader_decodesBase64(t *testing.T) {
+	reader := NewReader(NewSliceLineReader([]byte("begin-base64 644 hello.txt\nSGVsbG8g\nV29ybGQK\n====\n")))
+	contents, err := ioutil.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello World\n", string(contents))
+
+	fileInfo, _ := reader.FileInfo()
+	assert.Equal(t, &FileInfo{Encoding: Base64Encoding, Mode: os.FileMode(0644), Name: "hello.txt", decoded: 12, complete: true}, fileInfo)
+}
+
 func TestUuReader_ReadByte_readsInfo(t *testing.T) {
 	reader := NewReader(NewSliceLineReader([]byte("begin 000 hello.txt\n`\nend")))
 	b, err := reader.ReadByte()
@@ -119,7 +168,7 @@ func TestUuReader_ReadByte_readsInfo(t *testing.T) {
 
 	fileInfo, _ := reader.FileInfo()
 
-	assert.Equal(t, &FileInfo{encoding: uuEncoding, Mode: os.FileMode(0), Name: "hello.txt"}, fileInfo)
+	assert.Equal(t, &FileInfo{Encoding: UUEncoding, Mode: os.FileMode(0), Name: "hello.txt", complete: true}, fileInfo)
 }
 
 func TestUuReader_Read_readsInfo(t *testing.T) {
@@ -132,7 +181,7 @@ func TestUuReader_Read_readsInfo(t *testing.T) {
 
 	fileInfo, _ := reader.FileInfo()
 
-	assert.Equal(t, &FileInfo{encoding: uuEncoding, Mode: os.FileMode(0), Name: "hello.txt"}, fileInfo)
+	assert.Equal(t, &FileInfo{Encoding: UUEncoding, Mode: os.FileMode(0), Name: "hello.txt", complete: true}, fileInfo)
 }
 
 func TestUuReader_FileInfo_readError(t *testing.T) {
@@ -165,6 +214,60 @@ func TestUuReader_ReadByte_readError(t *testing.T) {
 	assert.Equal(t, expected, err)
 }
 
+func TestUuReader_truncated(t *testing.T) {
+	inputs := []string{
+		"begin 644 hello.txt\n",
+		"begin 644 hello.txt\n#0V%T\n",
+		"begin 644 hello.txt\n#0V%T\n`\n",
+		"begin 644 hello.txt\n#0V%T\n,2&5L;&\\@5V",
+		"begin-base64 644 hello.txt\nQ2F0\n",
+		"begin-base64 644 hello.txt\nQ2F0\nV29ybG",
+	}
+	for _, input := range inputs {
+		reader := NewReader(NewSliceLineReader([]byte(input)))
+		contents, err := ioutil.ReadAll(reader)
+
+		assert.Equal(t, ErrTruncated, err, input)
+		assert.Equal(t, io.ErrUnexpectedEOF, err.(interface{ Unwrap() error }).Unwrap())
+		assert.Equal(t, len(contents) == 3, strings.Contains(input, "Q2F0") || strings.Contains(input, "#0V%T"))
+
+		fileInfo, _ := reader.FileInfo()
+		assert.True(t, fileInfo.Truncated())
+		assert.False(t, fileInfo.Complete())
+	}
+}
+
+func TestUuReader_allowTruncated(t *testing.T) {
+	input := "begin 644 hello.txt\n#0V%T\n#0V%T\n,2&5L;&\\@5V"
+	reader := NewReaderWithOptions(NewSliceLineReader([]byte(input)), ReaderOptions{AllowTruncated: true})
+	contents, err := ioutil.ReadAll(reader)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "CatCat", string(contents))
+
+	fileInfo, _ := reader.FileInfo()
+	assert.True(t, fileInfo.Truncated())
+	assert.False(t, fileInfo.Complete())
+}
+
+func TestUuReader_shortLineNotLast(t *testing.T) {
+	reader := NewReader(NewSliceLineReader([]byte("begin 644 hello.txt\n#0V\n`\nend\n")))
+	_, err := ioutil.ReadAll(reader)
+
+	assert.Equal(t, newError("Input line too short"), err)
+	fileInfo, _ := reader.FileInfo()
+	assert.False(t, fileInfo.Truncated())
+}
+
+func TestUuReader_emptyInput(t *testing.T) {
+	reader := NewReader(NewSliceLineReader([]byte("")))
+	_, err := ioutil.ReadAll(reader)
+	assert.Nil(t, err)
+
+	_, err = reader.FileInfo()
+	assert.Equal(t, io.EOF, err)
+}
+
 func decodeWithReadByte(t *testing.T, reader io.ByteReader) []byte {
 	contents := make([]byte, 0)
 	b, err := reader.ReadByte()
@@ -205,7 +308,7 @@ func assertDecodes(t *testing.T, uuFileName string, mode os.FileMode, fileName s
 	assert.Nil(t, err)
 	assert.Equal(t, fileName, fileInfo.Name)
 	assert.Equal(t, mode, fileInfo.Mode)
-	assert.Equal(t, uuEncoding, fileInfo.encoding)
+	assert.Equal(t, UUEncoding, fileInfo.Encoding)
 
 	contents := decodeWithReadByte(t, r)
 	assert.Equal(t, expected, contents)
@@ -239,7 +342,7 @@ func assertEndLineFails(t *testing.T, in string, expected FileInfo) {
 }
 
 func assertPayloadLineParsed(t *testing.T, in string, expected string) {
-	fileInfo := FileInfo{encoding: uuEncoding, Mode: os.FileMode(0), Name: "hello.txt"}
+	fileInfo := FileInfo{Encoding: UUEncoding, Mode: os.FileMode(0), Name: "hello.txt"}
 	out := make([]byte, 0, len(expected))
 	out, err := parsePayloadLine(&fileInfo, []byte(in), out)
 	assert.Nil(t, err)
@@ -247,7 +350,7 @@ func assertPayloadLineParsed(t *testing.T, in string, expected string) {
 }
 
 func assertPayloadLineFails(t *testing.T, in string, expected error) {
-	fileInfo := FileInfo{encoding: uuEncoding, Mode: os.FileMode(0), Name: "hello.txt"}
+	fileInfo := FileInfo{Encoding: UUEncoding, Mode: os.FileMode(0), Name: "hello.txt"}
 	out := make([]byte, 0)
 	out, err := parsePayloadLine(&fileInfo, []byte(in), out)
 	assert.Nil(t, out)
@@ -0,0 +1,126 @@
+package uu
+
+import (
+	"io"
+)
+
+// WriterOptions controls optional parts of the encoded output
+type WriterOptions struct {
+	// LineChecksums appends a checksum character to each classic payload line
+	LineChecksums bool
+	// SumTrailer writes a sum -r/size line with the checksum and size of the input after the trailer
+	SumTrailer bool
+}
+
+type uuWriter struct {
+	writer  io.Writer
+	info    FileInfo
+	options WriterOptions
+	pending []byte
+	line    []byte
+	sum     bsdSum
+	started bool
+	err     error
+}
+
+// NewWriter creates an io.WriteCloser that UU encodes everything written to it into the provided io.Writer.
+//
+// The header is written along with the first encoded data, and Close must be called to write the last line
+// and the trailer. Close does not close the underlying io.Writer.
+func NewWriter(writer io.Writer, info FileInfo) io.WriteCloser {
+	return NewWriterWithOptions(writer, info, WriterOptions{})
+}
+
+// NewWriterWithOptions creates an io.WriteCloser like NewWriter, with the output controlled by options
+func NewWriterWithOptions(writer io.Writer, info FileInfo, options WriterOptions) io.WriteCloser {
+	return &uuWriter{
+		writer:  writer,
+		info:    info,
+		options: options,
+		pending: make([]byte, 0, maxLineBytes),
+		line:    make([]byte, 0, lineLength+1),
+	}
+}
+
+func (w *uuWriter) Write(b []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	if err := w.writeHeader(); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	if len(w.pending) > 0 {
+		n = copy(w.pending[len(w.pending):maxLineBytes], b)
+		w.pending = w.pending[:len(w.pending)+n]
+		if len(w.pending) < maxLineBytes {
+			return n, nil
+		}
+		if err := w.writeLine(w.pending); err != nil {
+			return 0, err
+		}
+		w.pending = w.pending[:0]
+	}
+
+	for ; len(b)-n >= maxLineBytes; n += maxLineBytes {
+		if err := w.writeLine(b[n : n+maxLineBytes]); err != nil {
+			return n, err
+		}
+	}
+
+	w.pending = append(w.pending, b[n:]...)
+	return len(b), nil
+}
+
+func (w *uuWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+
+	if len(w.pending) > 0 {
+		if err := w.writeLine(w.pending); err != nil {
+			return err
+		}
+		w.pending = w.pending[:0]
+	}
+	w.line = formatTrailer(&w.info, w.line[:0])
+	if w.options.SumTrailer {
+		w.line = formatSumLine(&w.sum, w.line)
+	}
+	if _, w.err = w.writer.Write(w.line); w.err != nil {
+		return w.err
+	}
+
+	w.err = newError("Write on closed writer")
+	return nil
+}
+
+func (w *uuWriter) writeHeader() error {
+	if !w.started {
+		w.started = true
+		if w.err = checkEncoding(&w.info); w.err != nil {
+			return w.err
+		}
+		if _, w.err = w.info.alphabet(); w.err != nil {
+			return w.err
+		}
+		_, w.err = w.writer.Write(formatBegin(&w.info, w.line[:0]))
+	}
+	return w.err
+}
+
+func (w *uuWriter) writeLine(in []byte) error {
+	w.line = encodePayloadLine(&w.info, in, w.line[:0])
+	if w.options.LineChecksums && w.info.Encoding != Base64Encoding {
+		w.line = append(w.line, lineChecksum(in))
+		fromStandard(&w.info, w.line[len(w.line)-1:])
+	}
+	w.line = append(w.line, '\n')
+	w.sum.Write(in)
+	_, w.err = w.writer.Write(w.line)
+	return w.err
+}
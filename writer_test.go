@@ -0,0 +1,127 @@
+package uu
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+type failingWriter struct {
+	remaining int
+}
+
+func (w *failingWriter) Write(b []byte) (int, error) {
+	if w.remaining < len(b) {
+		n := w.remaining
+		w.remaining = 0
+		return n, newError("write failed")
+	}
+	w.remaining -= len(b)
+	return len(b), nil
+}
+
+func testPayload(n int) []byte {
+	payload := make([]byte, n)
+	for i := range payload {
+		payload[i] = byte(i * 7)
+	}
+	return payload
+}
+
+func TestEncodePayloadLine(t *testing.T) {
+	assertPayloadLineEncoded(t, "a", "!80``")
+	assertPayloadLineEncoded(t, "ab", "\"86(`")
+	assertPayloadLineEncoded(t, "abc", "#86)C")
+	assertPayloadLineEncoded(t, "abcd", "$86)C9```")
+	assertPayloadLineEncoded(t, "abcde", "%86)C9&4`")
+	assertPayloadLineEncoded(t, "abcdef", "&86)C9&5F")
+	assertPayloadLineEncoded(t, "http://www.wikipedia.org\r\n", "::'1T<#HO+W=W=RYW:6MI<&5D:6$N;W)G#0H`")
+}
+
+func TestFormatBegin(t *testing.T) {
+	assert.Equal(t, "begin 000 hello.txt\n", string(formatBegin(&FileInfo{Name: "hello.txt"}, nil)))
+	assert.Equal(t, "begin 644 hello.txt\n", string(formatBegin(&FileInfo{Name: "hello.txt", Mode: 0644}, nil)))
+	assert.Equal(t, "begin 007 hello.txt\n", string(formatBegin(&FileInfo{Name: "hello.txt", Mode: 0007}, nil)))
+	assert.Equal(t, "begin 4755 tool\n", string(formatBegin(&FileInfo{Name: "tool", Mode: os.ModeSetuid | 0755}, nil)))
+	assert.Equal(t, "begin 3770 shared\n", string(formatBegin(&FileInfo{Name: "shared", Mode: os.ModeSetgid | os.ModeSticky | 0770}, nil)))
+	assert.Equal(t, "begin 644 hello.txt\n", string(formatBegin(&FileInfo{Name: "hello.txt", Mode: os.ModeDir | 0644}, nil)))
+}
+
+func TestUuWriter_encodesFile(t *testing.T) {
+	expected, err := ioutil.ReadFile("testdata/test.uu")
+	if err != nil {
+		panic(err)
+	}
+	contents, err := ioutil.ReadFile("testdata/test.bin")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, size := range []int{1, 2, 44, 45, 46, 4096} {
+		var out bytes.Buffer
+		w := NewWriter(&out, FileInfo{Name: "test.bin", Mode: 0644})
+
+		assert.Nil(t, writeInChunks(w, contents, size))
+		assert.Nil(t, w.Close())
+		assert.Equal(t, string(expected), out.String())
+	}
+}
+
+func TestUuWriter_roundTrip(t *testing.T) {
+	for n := 0; n < 200; n++ {
+		var out bytes.Buffer
+		w := NewWriter(&out, FileInfo{Name: "hello.txt", Mode: 0600})
+		_, err := w.Write(testPayload(n))
+		assert.Nil(t, err)
+		assert.Nil(t, w.Close())
+
+		info, contents, err := decodeSequential(out.Bytes())
+		assert.Nil(t, err)
+		assert.Equal(t, "hello.txt", info.Name)
+		assert.Equal(t, os.FileMode(0600), info.Mode)
+		assert.Equal(t, int64(n), info.Size())
+		assert.Equal(t, testPayload(n), contents)
+	}
+}
+
+func TestUuWriter_empty(t *testing.T) {
+	var out bytes.Buffer
+	w := NewWriter(&out, FileInfo{Name: "empty.txt", Mode: 0644})
+
+	assert.Nil(t, w.Close())
+	assert.Equal(t, "begin 644 empty.txt\n`\nend\n", out.String())
+}
+
+func TestUuWriter_writeError(t *testing.T) {
+	w := NewWriter(&failingWriter{remaining: 30}, FileInfo{Name: "hello.txt", Mode: 0644})
+
+	n, err := w.Write(testPayload(100))
+	assert.Equal(t, 0, n)
+	assert.EqualError(t, err, "write failed")
+	assert.EqualError(t, w.Close(), "write failed")
+}
+
+func TestUuWriter_writeAfterClose(t *testing.T) {
+	w := NewWriter(ioutil.Discard, FileInfo{Name: "hello.txt", Mode: 0644})
+	assert.Nil(t, w.Close())
+
+	_, err := w.Write([]byte("hello"))
+	assert.EqualError(t, err, "Write on closed writer")
+}
+
+func assertPayloadLineEncoded(t *testing.T, in string, expected string) {
+	out := encodePayloadLine(&FileInfo{}, []byte(in), nil)
+	assert.Equal(t, expected, string(out))
+}
+
+func TestUuWriter_invalidEncoding(t *testing.T) {
+	var out bytes.Buffer
+	w := NewWriter(&out, FileInfo{Encoding: Encoding(42), Name: "hello.txt", Mode: 0644})
+
+	_, err := w.Write([]byte("hello"))
+	assert.EqualError(t, err, "Invalid encoding")
+	assert.EqualError(t, w.Close(), "Invalid encoding")
+	assert.Equal(t, 0, out.Len())
+}